@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/homestead/internal/accuracy"
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/index"
+	_ "github.com/mjpitz/homestead/internal/index/backends"
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/config"
+	"github.com/mjpitz/myago/flagset"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+type Config struct {
+	ConfigFile string          `json:"config_file" usage:"specify the location of a file containing the configuration"`
+	Index      index.Config    `json:"index"`
+	Source     index.Config    `json:"source"`
+	Location   string          `json:"location"    usage:"the name of the location whose forecasts are scored" default:"home"`
+	Station    string          `json:"station"     usage:"only score forecasts against observations from this station"`
+	Window     time.Duration   `json:"window"      usage:"how far back to score forecasts, rounded to the start of the day" default:"168h"`
+	Accuracy   accuracy.Config `json:"accuracy"`
+	Log        zaputil.Config  `json:"log"`
+	DryRun     bool            `json:"dry_run"     usage:"print the scores instead of writing them" aliases:"dry-run"`
+}
+
+func printResults(results []*datasets.ForecastAccuracy) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "DATE\tSTATION\tFIELD\tLEAD TIME\tCOUNT\tMAE\tBIAS\tBRIER")
+
+	for _, result := range results {
+		brier := ""
+		if result.Brier != nil {
+			brier = fmt.Sprintf("%.3f", *result.Brier)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%dh\t%d\t%.3f\t%.3f\t%s\n",
+			result.Date.Format("2006-01-02"), result.Station, result.Field, result.LeadTime,
+			result.Count, result.MAE, result.Bias, brier)
+	}
+
+	return w.Flush()
+}
+
+func main() {
+	cfg := &Config{}
+
+	app := &cli.App{
+		Name:      "forecast-accuracy-index-builder",
+		Usage:     "Construct an index scoring past forecasts against observed conditions.",
+		UsageText: "forecast-accuracy-index-builder [options]",
+		Flags:     flagset.Extract(cfg),
+		Before: func(ctx *cli.Context) (err error) {
+			ctx.Context = zaputil.Setup(ctx.Context, cfg.Log)
+
+			if cfg.ConfigFile != "" {
+				err := config.Load(ctx.Context, cfg, cfg.ConfigFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+		Action: func(ctx *cli.Context) error {
+			// forecasts and observations are read through their own configuration so that the mode used to write
+			// the scores never affects how the weather tables are read
+			source := cfg.Source
+			if source.Endpoint == "" {
+				source.Endpoint = cfg.Index.Endpoint
+			}
+
+			action := func(ctx context.Context, idx index.Index) error {
+				reader, err := index.Open(source)
+				if err != nil {
+					return err
+				}
+				defer reader.Close()
+
+				querier, ok := reader.(index.Querier)
+				if !ok {
+					return fmt.Errorf("index backend does not support queries")
+				}
+
+				now := clocks.Extract(ctx).Now().UTC()
+				from := now.Add(-cfg.Window).Truncate(24 * time.Hour)
+
+				observations := make([]*datasets.Observation, 0)
+				err = querier.Query(ctx, index.Query{From: from, To: now, Location: cfg.Station}, &observations)
+				if err != nil {
+					return err
+				}
+
+				// every forecast revision is needed to score each lead time
+				forecasts := make([]*datasets.Weather, 0)
+				err = querier.Query(ctx, index.Query{From: from, To: now, Location: cfg.Location}, &forecasts)
+				if err != nil {
+					return err
+				}
+
+				results := accuracy.Evaluate(cfg.Accuracy, forecasts, observations, now)
+
+				zaputil.Extract(ctx).Info("scored forecasts",
+					zap.Int("forecasts", len(forecasts)),
+					zap.Int("observations", len(observations)),
+					zap.Int("scores", len(results)))
+
+				if cfg.DryRun {
+					return printResults(results)
+				}
+
+				docs := make([]interface{}, 0, len(results))
+				for _, result := range results {
+					docs = append(docs, result)
+				}
+
+				err = idx.Index(ctx, docs...)
+				if err != nil {
+					return err
+				}
+
+				zaputil.Extract(ctx).Info("done")
+				return nil
+			}
+
+			builder := index.Builder{Action: action}
+
+			return builder.Run(ctx.Context, cfg.Index)
+		},
+		HideVersion:          true,
+		HideHelpCommand:      true,
+		EnableBashCompletion: true,
+		BashComplete:         cli.DefaultAppComplete,
+		Metadata: map[string]interface{}{
+			"arch":       runtime.GOARCH,
+			"go_version": strings.TrimPrefix(runtime.Version(), "go"),
+			"os":         runtime.GOOS,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
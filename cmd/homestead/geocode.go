@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/apis/transport"
+	"github.com/mjpitz/homestead/internal/geo"
+	"github.com/mjpitz/myago/flagset"
+)
+
+type GeocodeConfig struct {
+	HTTP      transport.Config `json:"http"`
+	Geocoding geocoding.Config `json:"geocoding"`
+}
+
+func (c *GeocodeConfig) client() *geocoding.Client {
+	client := geocoding.NewClient(transport.NewClient(c.HTTP))
+	client.Configure(c.Geocoding)
+
+	return client
+}
+
+type GeographiesConfig struct {
+	GeocodeConfig
+	Coordinates string `json:"coordinates" usage:"latitude and longitude (e.g. 39.0473,-95.6752), geo: URI, or plus code of the location"`
+}
+
+type BatchConfig struct {
+	GeocodeConfig
+	File        string `json:"file"        usage:"CSV file of addresses to geocode (id, street, city, state, zip)"`
+	Geographies bool   `json:"geographies" usage:"also return the FIPS codes of the geographies containing each address"`
+}
+
+func benchmarksCommand() *cli.Command {
+	cfg := &GeocodeConfig{}
+
+	return &cli.Command{
+		Name:      "benchmarks",
+		Usage:     "List the benchmarks (versions of the address data) supported by the census geocoder.",
+		UsageText: "homestead geocode benchmarks [options]",
+		Flags:     flagset.Extract(cfg),
+		Action: func(ctx *cli.Context) error {
+			benchmarks, err := cfg.client().GetBenchmarks(ctx.Context)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(ctx.App.Writer, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "ID\tNAME\tDEFAULT\tDESCRIPTION")
+
+			for _, benchmark := range benchmarks {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n",
+					benchmark.ID, benchmark.Name, benchmark.IsDefault, benchmark.Description)
+			}
+
+			return tw.Flush()
+		},
+	}
+}
+
+func vintagesCommand() *cli.Command {
+	cfg := &GeocodeConfig{}
+
+	return &cli.Command{
+		Name:      "vintages",
+		Usage:     "List the vintages (versions of the census geographies) available for the selected benchmark.",
+		UsageText: "homestead geocode vintages [options]",
+		Flags:     flagset.Extract(cfg),
+		Action: func(ctx *cli.Context) error {
+			vintages, err := cfg.client().GetVintages(ctx.Context, "")
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(ctx.App.Writer, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "ID\tNAME\tDEFAULT\tDESCRIPTION")
+
+			for _, vintage := range vintages {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n",
+					vintage.ID, vintage.Name, vintage.IsDefault, vintage.Description)
+			}
+
+			return tw.Flush()
+		},
+	}
+}
+
+func geographiesCommand() *cli.Command {
+	cfg := &GeographiesConfig{}
+
+	return &cli.Command{
+		Name:      "geographies",
+		Usage:     "Print the state, county, tract, and block containing a location.",
+		UsageText: "homestead geocode geographies --coordinates <coordinates> [options]",
+		Flags:     flagset.Extract(cfg),
+		Action: func(ctx *cli.Context) error {
+			point, err := geo.Parse(cfg.Coordinates)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cfg.client().SearchGeographiesByCoordinates(ctx.Context,
+				float32(point.Latitude), float32(point.Longitude))
+			if err != nil {
+				return err
+			}
+
+			geographies := geocoding.Geographies{}
+			if resp.Result != nil {
+				geographies = resp.Result.Geographies
+			}
+
+			tw := tabwriter.NewWriter(ctx.App.Writer, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "GEOGRAPHY\tNAME\tFIPS")
+
+			for _, row := range []struct {
+				name      string
+				geography *geocoding.Geography
+			}{
+				{"state", geographies.State()},
+				{"county", geographies.County()},
+				{"tract", geographies.Tract()},
+				{"block", geographies.Block()},
+			} {
+				if row.geography == nil {
+					continue
+				}
+
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", row.name, row.geography.Name, row.geography.GEOID)
+			}
+
+			return tw.Flush()
+		},
+	}
+}
+
+func batchCommand() *cli.Command {
+	cfg := &BatchConfig{}
+
+	return &cli.Command{
+		Name:      "batch",
+		Usage:     "Geocode a CSV file of addresses, printing the results as CSV.",
+		UsageText: "homestead geocode batch --file <addresses.csv> [options]",
+		Flags:     flagset.Extract(cfg),
+		Action: func(ctx *cli.Context) error {
+			file, err := os.Open(cfg.File)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			addresses, err := geocoding.ReadBatch(file)
+			if err != nil {
+				return err
+			}
+
+			client := cfg.client()
+
+			search := client.SearchByAddressBatch
+			if cfg.Geographies {
+				search = client.SearchGeographiesByAddressBatch
+			}
+
+			results, err := search(ctx.Context, addresses)
+			if err != nil {
+				return err
+			}
+
+			w := csv.NewWriter(ctx.App.Writer)
+
+			err = w.Write([]string{
+				"id", "match", "exactness", "matched_address", "latitude", "longitude",
+				"state_fips", "county_fips", "tract_fips", "block_fips",
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, result := range results {
+				latitude, longitude := "", ""
+				if result.Coordinates != nil {
+					latitude = strconv.FormatFloat(float64(result.Coordinates.Y), 'f', -1, 32)
+					longitude = strconv.FormatFloat(float64(result.Coordinates.X), 'f', -1, 32)
+				}
+
+				fips := geocoding.FIPS{}
+				if result.FIPS != nil {
+					fips = *result.FIPS
+				}
+
+				err = w.Write([]string{
+					result.ID, result.Match, result.Exactness, result.MatchedAddress, latitude, longitude,
+					fips.State, fips.County, fips.Tract, fips.Block,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			w.Flush()
+			return w.Error()
+		},
+	}
+}
+
+var geocodeCommand = &cli.Command{
+	Name:      "geocode",
+	Usage:     "Work with the census geocoder.",
+	UsageText: "homestead geocode <command> [options]",
+	Subcommands: []*cli.Command{
+		benchmarksCommand(),
+		vintagesCommand(),
+		geographiesCommand(),
+		batchCommand(),
+	},
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	_ "github.com/mjpitz/homestead/internal/index/backends"
+	"github.com/mjpitz/myago/flagset"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+type Config struct {
+	Log zaputil.Config `json:"log"`
+}
+
+func main() {
+	cfg := &Config{}
+
+	app := &cli.App{
+		Name:      "homestead",
+		Usage:     "Tools for working with the data collected about a homestead.",
+		UsageText: "homestead <command> [options]",
+		Flags:     flagset.Extract(cfg),
+		Commands: []*cli.Command{
+			geocodeCommand,
+			queryCommand,
+		},
+		Before: func(ctx *cli.Context) error {
+			ctx.Context = zaputil.Setup(ctx.Context, cfg.Log)
+
+			return nil
+		},
+		HideVersion:          true,
+		HideHelpCommand:      true,
+		EnableBashCompletion: true,
+		BashComplete:         cli.DefaultAppComplete,
+		Metadata: map[string]interface{}{
+			"arch":       runtime.GOARCH,
+			"go_version": strings.TrimPrefix(runtime.Version(), "go"),
+			"os":         runtime.GOOS,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/flagset"
+)
+
+type QueryConfig struct {
+	Index    index.Config     `json:"index"`
+	From     string           `json:"from"     usage:"start of the time range (RFC 3339, now, or a duration relative to now such as -6h)" default:"now"`
+	To       string           `json:"to"       usage:"end of the time range (RFC 3339, now, or a duration relative to now such as 24h)" default:"24h"`
+	Fields   *cli.StringSlice `json:"fields"   usage:"the fields to print (defaults to every field)"`
+	Location string           `json:"location" usage:"only return documents for the named location"`
+	Latest   bool             `json:"latest"   usage:"only return the latest forecast for each point in time"`
+	Format   string           `json:"format"   usage:"how results are printed (table, json)" default:"table"`
+}
+
+// parseTime accepts an RFC 3339 timestamp, the literal "now", or a duration relative to now.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	switch value {
+	case "":
+		return time.Time{}, nil
+	case "now":
+		return now, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func formatValue(value reflect.Value) string {
+	// optional columns that were not set leave their cell empty
+	if !value.IsValid() {
+		return ""
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return ""
+		}
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v.Local().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+// printResults writes each document using the requested format, limited to the time column and requested fields.
+func printResults(w io.Writer, format string, fields []string, docs []interface{}) error {
+	rows := make([][]index.Column, 0, len(docs))
+
+	for _, doc := range docs {
+		cols, err := index.Columns(doc)
+		if err != nil {
+			return err
+		}
+
+		timeKey := doc.(index.TimeSeries).TimeKey()
+		selected := make([]index.Column, 0, len(cols))
+
+		for _, col := range cols {
+			if len(fields) == 0 || col.Name == timeKey {
+				selected = append(selected, col)
+				continue
+			}
+
+			for _, field := range fields {
+				if field == col.Name {
+					selected = append(selected, col)
+				}
+			}
+		}
+
+		rows = append(rows, selected)
+	}
+
+	switch format {
+	case "json":
+		results := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			result := make(map[string]interface{}, len(row))
+			for _, col := range row {
+				if col.Value.IsValid() {
+					result[col.Name] = col.Value.Interface()
+				}
+			}
+
+			results = append(results, result)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(results)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+		for i, row := range rows {
+			if i == 0 {
+				names := make([]string, 0, len(row))
+				for _, col := range row {
+					names = append(names, strings.ToUpper(col.Name))
+				}
+
+				_, _ = fmt.Fprintln(tw, strings.Join(names, "\t"))
+			}
+
+			values := make([]string, 0, len(row))
+			for _, col := range row {
+				values = append(values, formatValue(col.Value))
+			}
+
+			_, _ = fmt.Fprintln(tw, strings.Join(values, "\t"))
+		}
+
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("unrecognized format: %s", format)
+}
+
+// query constructs a command that reads documents of the provided type back out of an index.
+func query(name, usage string, newResults func() interface{}) *cli.Command {
+	cfg := &QueryConfig{
+		Fields: cli.NewStringSlice(),
+	}
+
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		UsageText: fmt.Sprintf("homestead query %s [options]", name),
+		Flags:     flagset.Extract(cfg),
+		Action: func(ctx *cli.Context) error {
+			now := clocks.Extract(ctx.Context).Now()
+
+			from, err := parseTime(cfg.From, now)
+			if err != nil {
+				return err
+			}
+
+			to, err := parseTime(cfg.To, now)
+			if err != nil {
+				return err
+			}
+
+			idx, err := index.Open(cfg.Index)
+			if err != nil {
+				return err
+			}
+			defer idx.Close()
+
+			querier, ok := idx.(index.Querier)
+			if !ok {
+				return fmt.Errorf("index %s does not support queries", index.Scheme(cfg.Index.Endpoint))
+			}
+
+			results := newResults()
+
+			err = querier.Query(ctx.Context, index.Query{
+				From:       from,
+				To:         to,
+				Fields:     cfg.Fields.Value(),
+				Location:   cfg.Location,
+				LatestOnly: cfg.Latest,
+			}, results)
+			if err != nil {
+				return err
+			}
+
+			value := reflect.ValueOf(results).Elem()
+			docs := make([]interface{}, 0, value.Len())
+
+			for i := 0; i < value.Len(); i++ {
+				docs = append(docs, value.Index(i).Interface())
+			}
+
+			return printResults(ctx.App.Writer, cfg.Format, cfg.Fields.Value(), docs)
+		},
+	}
+}
+
+var queryCommand = &cli.Command{
+	Name:      "query",
+	Usage:     "Read documents back out of an index.",
+	UsageText: "homestead query <dataset> [options]",
+	Subcommands: []*cli.Command{
+		query("weather", "Query forecasted weather readings.", func() interface{} {
+			return &[]*datasets.Weather{}
+		}),
+		query("observations", "Query conditions observed by weather stations.", func() interface{} {
+			return &[]*datasets.Observation{}
+		}),
+		query("accuracy", "Query how well past forecasts matched observed conditions.", func() interface{} {
+			return &[]*datasets.ForecastAccuracy{}
+		}),
+		query("alerts", "Query weather alerts by the time they were sent.", func() interface{} {
+			return &[]*datasets.Alert{}
+		}),
+	},
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/datasets"
+)
+
+func TestPrintResultsTable(t *testing.T) {
+	start := time.Date(2022, 1, 12, 15, 0, 0, 0, time.UTC)
+	temperature, dewpoint := -2.5, -6.0
+
+	docs := []interface{}{
+		&datasets.Observation{Station: "KTOP", Timestamp: start, Temperature: &temperature, Dewpoint: &dewpoint},
+		// stations don't always report every measurement
+		&datasets.Observation{Station: "KTOP", Timestamp: start.Add(time.Hour), Temperature: &temperature},
+	}
+
+	out := &bytes.Buffer{}
+	err := printResults(out, "table", []string{"temperature_degc", "dewpoint_degc"}, docs)
+	require.NoError(t, err)
+	require.NotContains(t, out.String(), "<nil>")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"TIMESTAMP", "TEMPERATURE_DEGC", "DEWPOINT_DEGC"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{start.Local().Format(time.RFC3339), "-2.5", "-6"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{start.Add(time.Hour).Local().Format(time.RFC3339), "-2.5"}, strings.Fields(lines[2]))
+}
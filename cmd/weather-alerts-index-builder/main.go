@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/homestead/internal/alerts"
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/apis/transport"
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/geo"
+	"github.com/mjpitz/homestead/internal/index"
+	_ "github.com/mjpitz/homestead/internal/index/backends"
+	"github.com/mjpitz/homestead/internal/index/memory"
+	"github.com/mjpitz/homestead/internal/state"
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/config"
+	"github.com/mjpitz/myago/flagset"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+type Config struct {
+	ConfigFile      string            `json:"config_file"      usage:"specify the location of a file containing the configuration"`
+	Index           index.Config      `json:"index"`
+	Coordinates     string            `json:"coordinates"      usage:"latitude and longitude (e.g. 39.0473,-95.6752), geo: URI, or plus code used instead of the address"`
+	Address         geocoding.Address `json:"address"`
+	Geocoding       geocoding.Config  `json:"geocoding"`
+	Zone            string            `json:"zone"             usage:"retrieve alerts for a forecast zone (e.g. KSZ040) instead of the location"`
+	HTTP            transport.Config  `json:"http"`
+	State           state.Config      `json:"state"`
+	LocationTTL     time.Duration     `json:"location_ttl"     usage:"how long geocoded addresses are reused from the state file (0 disables)" default:"720h"`
+	RefreshLocation bool              `json:"refresh_location" usage:"resolve the location again instead of reusing the state file" aliases:"refresh-location"`
+	Log             zaputil.Config    `json:"log"`
+	DryRun          bool              `json:"dry_run"          usage:"collect documents in memory and print a summary instead of writing them" aliases:"dry-run"`
+}
+
+func main() {
+	cfg := &Config{}
+
+	app := &cli.App{
+		Name:      "weather-alerts-index-builder",
+		Usage:     "Construct an index with active weather alerts.",
+		UsageText: "weather-alerts-index-builder [options]",
+		Flags:     flagset.Extract(cfg),
+		Before: func(ctx *cli.Context) (err error) {
+			ctx.Context = zaputil.Setup(ctx.Context, cfg.Log)
+
+			if cfg.ConfigFile != "" {
+				err := config.Load(ctx.Context, cfg, cfg.ConfigFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+		Action: func(ctx *cli.Context) error {
+			action := func(ctx context.Context, index index.Index) error {
+				if cfg.State.Path == "" {
+					zaputil.Extract(ctx).Warn("without --state_path, alerts that are updated or cancelled after they " +
+						"are no longer active will not be marked as superseded")
+				}
+
+				store, err := state.Open(cfg.State)
+				if err != nil {
+					return err
+				}
+
+				httpClient := transport.NewClient(cfg.HTTP)
+				geocodingAPI := geocoding.NewClient(httpClient)
+				geocodingAPI.Configure(cfg.Geocoding)
+				weatherAPI := weather.NewClient(httpClient)
+
+				area := weather.AlertArea{Zone: cfg.Zone}
+				if area.Zone == "" {
+					cache := &geo.Cache{Store: store, TTL: cfg.LocationTTL, Refresh: cfg.RefreshLocation}
+
+					place, err := cache.Locate(ctx, geocodingAPI, cfg.Coordinates, cfg.Address)
+					if err != nil {
+						return err
+					}
+
+					area.Point = &weather.Coordinates{
+						Latitude:  float32(place.Latitude),
+						Longitude: float32(place.Longitude),
+					}
+				}
+
+				active, err := weatherAPI.GetActiveAlerts(ctx, area)
+				if err != nil {
+					return err
+				}
+
+				stateKey := "alerts/zone/" + area.Zone
+				if area.Point != nil {
+					stateKey = fmt.Sprintf("alerts/point/%.4f,%.4f", area.Point.Latitude, area.Point.Longitude)
+				}
+
+				previous := make(map[string]*datasets.Alert)
+				_, err = store.Get(stateKey, &previous)
+				if err != nil {
+					return err
+				}
+
+				tracked, current, err := alerts.Track(previous, active, clocks.Extract(ctx).Now())
+				if err != nil {
+					return err
+				}
+
+				docs := make([]interface{}, 0, len(tracked))
+				for _, doc := range tracked {
+					docs = append(docs, doc)
+				}
+
+				zaputil.Extract(ctx).Info("writing documents",
+					zap.Int("active", len(active)),
+					zap.Int("num", len(docs)))
+
+				err = index.Index(ctx, docs...)
+				if err != nil {
+					return err
+				}
+
+				if !cfg.DryRun {
+					err = store.Set(stateKey, current)
+					if err == nil {
+						err = store.Save()
+					}
+
+					if err != nil {
+						return err
+					}
+				}
+
+				if mem, ok := index.(*memory.Index); ok && cfg.DryRun {
+					for _, summary := range mem.Summarize() {
+						fmt.Printf("would write %d %s documents from %s to %s\n", summary.Count, summary.Table,
+							summary.From.Format(time.RFC3339), summary.To.Format(time.RFC3339))
+					}
+				}
+
+				zaputil.Extract(ctx).Info("done")
+				return nil
+			}
+
+			if cfg.DryRun {
+				cfg.Index.Endpoint = "memory://"
+				cfg.HTTP.Cache.Directory = ""
+			}
+
+			builder := index.Builder{Action: action}
+
+			return builder.Run(ctx.Context, cfg.Index)
+		},
+		HideVersion:          true,
+		HideHelpCommand:      true,
+		EnableBashCompletion: true,
+		BashComplete:         cli.DefaultAppComplete,
+		Metadata: map[string]interface{}{
+			"arch":       runtime.GOARCH,
+			"go_version": strings.TrimPrefix(runtime.Version(), "go"),
+			"os":         runtime.GOOS,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -2,134 +2,328 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
 
 	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/apis/transport"
 	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/geo"
 	"github.com/mjpitz/homestead/internal/index"
-	"github.com/mjpitz/homestead/internal/index/postgres"
+	_ "github.com/mjpitz/homestead/internal/index/backends"
+	"github.com/mjpitz/homestead/internal/index/memory"
+	"github.com/mjpitz/homestead/internal/resample"
+	"github.com/mjpitz/homestead/internal/state"
+	"github.com/mjpitz/homestead/internal/units"
 	"github.com/mjpitz/myago/clocks"
 	"github.com/mjpitz/myago/config"
 	"github.com/mjpitz/myago/flagset"
 	"github.com/mjpitz/myago/zaputil"
 )
 
-type Weather struct {
-	Timestamp  time.Time `json:"timestamp" gorm:"index"`
-	ObservedAt time.Time `json:"observed_at"`
-
-	Elevation                        float64 `json:"elevation_m"`
-	Temperature                      float64 `json:"temperature_degc"`
-	Dewpoint                         float64 `json:"dewpoint_degc"`
-	MaxTemperature                   float64 `json:"max_temperature_degc"`
-	MinTemperature                   float64 `json:"min_temperature_degc"`
-	RelativeHumidity                 float64 `json:"relative_humidity_pct"`
-	ApparentTemperature              float64 `json:"apparent_temperature_degc"`
-	HeatIndex                        float64 `json:"heat_index_degc"`
-	WindChill                        float64 `json:"wind_chill_degc"`
-	SkyCover                         float64 `json:"sky_cover_pct"`
-	WindDirection                    float64 `json:"wind_direction"`
-	WindSpeed                        float64 `json:"wind_speed_kph"` // kilometers per hour
-	WindGust                         float64 `json:"wind_gust_kph"`  // kilometers per hour
-	ProbabilityOfPrecipitation       float64 `json:"precipitation_probability_pct"`
-	QuantitativePrecipitation        float64 `json:"precipitation_quantity_mm"`
-	IceAccumulation                  float64 `json:"ice_accumulation_mm"`
-	SnowfallAmount                   float64 `json:"snowfall_amount_mm"`
-	SnowLevel                        float64 `json:"snow_level"`
-	CeilingHeight                    float64 `json:"ceiling_height"`
-	Visibility                       float64 `json:"visibility"`
-	TransportWindSpeed               float64 `json:"transport_wind_speed_kph"`
-	TransportWindDirection           float64 `json:"transport_wind_direction"`
-	MixingHeight                     float64 `json:"mixing_height_m"`
-	HainesIndex                      float64 `json:"haines_index"`
-	LightningActivityLevel           float64 `json:"lightning_activity_level"`
-	TwentyFootWindSpeed              float64 `json:"twenty_foot_wind_speed_kph"`
-	TwentyFootWindDirection          float64 `json:"twenty_foot_wind_direction"`
-	WaveHeight                       float64 `json:"wave_height"`
-	WavePeriod                       float64 `json:"wave_period"`
-	PrimarySwellHeight               float64 `json:"primary_swell_height"`
-	PrimarySwellDirection            float64 `json:"primary_swell_direction"`
-	SecondarySwellHeight             float64 `json:"secondary_swell_height"`
-	SecondarySwellDirection          float64 `json:"secondary_swell_direction"`
-	WavePeriod2                      float64 `json:"wave_period_2"`
-	WindWaveHeight                   float64 `json:"wind_wave_height"`
-	DispersionIndex                  float64 `json:"dispersion_index"`
-	Pressure                         float64 `json:"pressure"`
-	ProbabilityOfTropicalStormWinds  float64 `json:"probability_of_tropical_storm_winds"`
-	ProbabilityOfHurricaneWinds      float64 `json:"probability_of_hurricane_winds"`
-	PotentialOf15mphWinds            float64 `json:"potential_of_15_mph_winds"`
-	PotentialOf25mphWinds            float64 `json:"potential_of_25_mph_winds"`
-	PotentialOf35mphWinds            float64 `json:"potential_of_35_mph_winds"`
-	PotentialOf45mphWinds            float64 `json:"potential_of_45_mph_winds"`
-	PotentialOf20mphWindGusts        float64 `json:"potential_of_20_mph_wind_gusts"`
-	PotentialOf30mphWindGusts        float64 `json:"potential_of_30_mph_wind_gusts"`
-	PotentialOf40mphWindGusts        float64 `json:"potential_of_40_mph_wind_gusts"`
-	PotentialOf50mphWindGusts        float64 `json:"potential_of_50_mph_wind_gusts"`
-	PotentialOf60mphWindGusts        float64 `json:"potential_of_60_mph_wind_gusts"`
-	GrasslandFireDangerIndex         float64 `json:"grassland_fire_danger_index"`
-	ProbabilityOfThunder             float64 `json:"probability_of_thunder"`
-	DavisStabilityIndex              float64 `json:"davis_stability_index"`
-	AtmosphericDispersionIndex       float64 `json:"atmospheric_dispersion_index"`
-	LowVisibilityOccurrenceRiskIndex float64 `json:"low_visibility_occurrence_risk_index"`
-	Stability                        float64 `json:"stability"`
-	RedFlagThreatIndex               float64 `json:"red_flag_threat_index"`
+// Location is a named site to index the forecast for. The address is only geocoded when no coordinates are provided,
+// either as a latitude and longitude or using the coordinates field.
+type Location struct {
+	Name        string            `json:"name"`
+	Coordinates string            `json:"coordinates"`
+	Latitude    float64           `json:"latitude"`
+	Longitude   float64           `json:"longitude"`
+	Address     geocoding.Address `json:"address"`
 }
 
-func (w Weather) TableName() string {
-	return "weather"
+type Config struct {
+	ConfigFile      string            `json:"config_file"      usage:"specify the location of a file containing the configuration"`
+	Index           index.Config      `json:"index"`
+	Location        string            `json:"location"         usage:"name used to identify the forecast for the address or coordinates" default:"home"`
+	Coordinates     string            `json:"coordinates"      usage:"latitude and longitude (e.g. 39.0473,-95.6752), geo: URI, or plus code used instead of the address"`
+	Address         geocoding.Address `json:"address"`
+	Geocoding       geocoding.Config  `json:"geocoding"`
+	Locations       []Location        `json:"locations"` // only configurable using the config file
+	Concurrency     int               `json:"concurrency"      usage:"maximum number of locations retrieved at the same time" default:"4"`
+	HTTP            transport.Config  `json:"http"`
+	State           state.Config      `json:"state"`
+	LocationTTL     time.Duration     `json:"location_ttl"     usage:"how long geocoded addresses and weather.gov grids are reused from the state file (0 disables)" default:"720h"`
+	RefreshLocation bool              `json:"refresh_location" usage:"resolve the location again instead of reusing the state file" aliases:"refresh-location"`
+	Log             zaputil.Config    `json:"log"`
+	Resample        resample.Config   `json:"resample"`
+	Imperial        bool              `json:"imperial"         usage:"also populate imperial columns (degrees fahrenheit, miles per hour, and inches)"`
+	DryRun          bool              `json:"dry_run"          usage:"collect documents in memory and print a summary instead of writing them" aliases:"dry-run"`
 }
 
-type Config struct {
-	ConfigFile string            `json:"config_file" usage:"specify the location of a file containing the configuration"`
-	Index      index.Config      `json:"index"`
-	Address    geocoding.Address `json:"address"`
-	Log        zaputil.Config    `json:"log"`
+// locations returns the sites to index. When no list of locations is configured, the address is used instead.
+func (c *Config) locations() ([]Location, error) {
+	if len(c.Locations) == 0 {
+		return []Location{{Name: c.Location, Coordinates: c.Coordinates, Address: c.Address}}, nil
+	}
+
+	seen := make(map[string]bool, len(c.Locations))
+	for _, location := range c.Locations {
+		switch {
+		case location.Name == "":
+			return nil, fmt.Errorf("locations require a name")
+		case seen[location.Name]:
+			return nil, fmt.Errorf("location %q is configured more than once", location.Name)
+		}
+
+		seen[location.Name] = true
+	}
+
+	return c.Locations, nil
 }
 
-var docFrequency = 15 * time.Minute
+// updater expands gridpoint data into documents, converting each value into the unit of the column it is written to.
+// The first error encountered is retained and all later updates are skipped.
+type updater struct {
+	idx        map[int64]*datasets.Weather
+	strategy   resample.Strategy
+	resolution time.Duration
+	err        error
+}
+
+func (u *updater) update(gridpoints *weather.GridpointProperties, mapping *datasets.GridpointMapping) {
+	points := mapping.DataPoints(gridpoints)
+	if u.err != nil || points == nil {
+		return
+	}
 
-func update(idx map[int64]*Weather, points *weather.DataPoints, set func(w *Weather, value float64)) {
+	intervals := make([]resample.Interval, 0, len(points.Values))
 	for _, measure := range points.Values {
-		d := measure.ValidTime.Duration
-		t := measure.ValidTime.Time
-		millis := t.UnixMilli()
+		value, err := units.ConvertCode(float64(measure.Value), points.UnitOfMeasure, mapping.Unit)
+		if err != nil {
+			u.err = fmt.Errorf("failed to convert %s: %w", mapping.Source, err)
+			return
+		}
+
+		intervals = append(intervals, resample.Interval{
+			Start:    measure.ValidTime.Time,
+			Duration: measure.ValidTime.Duration,
+			Value:    value,
+		})
+	}
 
-		if _, ok := idx[millis]; !ok {
-			idx[millis] = &Weather{
-				Timestamp: t,
+	strategy := mapping.Strategy
+	if strategy == "" {
+		strategy = u.strategy
+	}
+
+	samples, err := resample.Resample(intervals, strategy, u.resolution)
+	if err != nil {
+		u.err = fmt.Errorf("failed to resample %s: %w", mapping.Source, err)
+		return
+	}
+
+	for _, sample := range samples {
+		millis := sample.Time.UnixMilli()
+
+		if _, ok := u.idx[millis]; !ok {
+			u.idx[millis] = &datasets.Weather{
+				Timestamp: sample.Time,
 			}
 		}
 
-		if d == 0 {
-			// no duration, single document
-			set(idx[millis], float64(measure.Value))
-			return
+		mapping.Set(u.idx[millis], sample.Value)
+	}
+}
+
+// forecast contains the documents produced for a single location along with the state recorded once they have been
+// indexed. No readings are produced when the forecast has not changed since the last run.
+type forecast struct {
+	location *datasets.Location
+	docs     []interface{}
+	stateKey string
+	issuedAt time.Time
+}
+
+// collector retrieves and expands the forecast for each location.
+type collector struct {
+	cfg          *Config
+	mappings     []*datasets.GridpointMapping
+	strategy     resample.Strategy
+	store        *state.Store
+	cache        *geo.Cache
+	geocodingAPI *geocoding.Client
+	weatherAPI   *weather.Client
+}
+
+// resolve locates the site, geocoding its address when no coordinates are provided, and determines the weather.gov grid covering it.
+func (c *collector) resolve(ctx context.Context, location Location) (*datasets.Location, error) {
+	place := &geo.Place{Point: geo.Point{Latitude: location.Latitude, Longitude: location.Longitude}}
+	err := place.Validate()
+
+	if location.Latitude == 0 && location.Longitude == 0 {
+		place, err = c.cache.Locate(ctx, c.geocodingAPI, location.Coordinates, location.Address)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &datasets.Location{
+		Name:      location.Name,
+		UpdatedAt: clocks.Extract(ctx).Now(),
+		Address:   place.Address,
+		Latitude:  place.Latitude,
+		Longitude: place.Longitude,
+	}
+
+	// points rarely move between grids, so they are cached alongside geocoded addresses
+	point := &weather.PointProperties{}
+	err = c.cache.Lookup(ctx, "locations/points/"+place.String(), point, func() error {
+		resolvedPoint, err := c.weatherAPI.GetPoint(ctx, float32(resolved.Latitude), float32(resolved.Longitude))
+		if err != nil {
+			return err
 		}
 
-		// expand window
+		*point = *resolvedPoint
+		return nil
+	})
 
-		for d > 0 {
-			millis := t.UnixMilli()
+	if err != nil {
+		return nil, err
+	}
 
-			if _, ok := idx[millis]; !ok {
-				idx[millis] = &Weather{
-					Timestamp: t,
-				}
+	resolved.GridID = point.GridID
+	resolved.GridX = point.GridX
+	resolved.GridY = point.GridY
+	resolved.TimeZone = point.TimeZone
+	resolved.RadarStation = point.RadarStation
+
+	return resolved, nil
+}
+
+// collect returns the forecast for the location.
+func (c *collector) collect(ctx context.Context, location Location) (*forecast, error) {
+	log := zaputil.Extract(ctx).With(zap.String("location", location.Name))
+
+	site, err := c.resolve(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	gridpoints, err := c.weatherAPI.GetGridpoint(ctx, site.GridID, site.GridX, site.GridY)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt, err := time.Parse(time.RFC3339, gridpoints.UpdateTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gridpoint update time: %w", err)
+	}
+
+	// the update time is tracked per location, and only recorded once its readings have been indexed, so locations
+	// sharing a grid and runs that failed to write are never skipped
+	stateKey := fmt.Sprintf("locations/%s/update_time", location.Name)
+
+	lastIssuedAt := time.Time{}
+	_, err = c.store.Get(stateKey, &lastIssuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lastIssuedAt.IsZero() && issuedAt.Equal(lastIssuedAt) {
+		log.Info("no new forecast since " + gridpoints.UpdateTime)
+		return &forecast{location: site}, nil
+	}
+
+	idx := make(map[int64]*datasets.Weather)
+	u := &updater{idx: idx, strategy: c.strategy, resolution: c.cfg.Resample.Resolution}
+
+	log.Info("updating datapoints")
+	for _, mapping := range c.mappings {
+		u.update(gridpoints, mapping)
+	}
+
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	elevation := 0.0
+	if gridpoints.Elevation != nil {
+		elevation, err = units.ConvertCode(float64(gridpoints.Elevation.Value), gridpoints.Elevation.UnitCode, units.Meters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert elevation: %w", err)
+		}
+	}
+
+	observedAt := clocks.Extract(ctx).Now()
+
+	docs := make([]interface{}, 0, len(idx))
+	for _, doc := range idx {
+		doc.ObservedAt = observedAt
+		doc.IssuedAt = issuedAt
+		doc.Location = location.Name
+		doc.Elevation = elevation
+
+		if c.cfg.Imperial {
+			doc.SetImperial()
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return &forecast{location: site, docs: docs, stateKey: stateKey, issuedAt: issuedAt}, nil
+}
+
+// collectAll retrieves the forecast for every location using a bounded number of workers. Forecasts that were
+// successfully retrieved are returned alongside an error describing any locations that failed.
+func (c *collector) collectAll(ctx context.Context, locations []Location) ([]*forecast, error) {
+	concurrency := c.cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	forecasts := make([]*forecast, len(locations))
+	errs := make([]error, len(locations))
+
+	work := make(chan int)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < concurrency && i < len(locations); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range work {
+				forecasts[j], errs[j] = c.collect(ctx, locations[j])
 			}
+		}()
+	}
+
+	for i := range locations {
+		work <- i
+	}
+
+	close(work)
+	wg.Wait()
 
-			set(idx[millis], float64(measure.Value))
+	collected := make([]*forecast, 0, len(locations))
+	failures := make([]string, 0)
 
-			d = d - docFrequency
-			t = t.Add(docFrequency)
+	for i, location := range locations {
+		switch {
+		case errs[i] != nil:
+			failures = append(failures, fmt.Sprintf("%s: %v", location.Name, errs[i]))
+		default:
+			collected = append(collected, forecasts[i])
 		}
 	}
+
+	if len(failures) > 0 {
+		return collected, fmt.Errorf("failed to retrieve forecast for %d location(s): %s",
+			len(failures), strings.Join(failures, "; "))
+	}
+
+	return collected, nil
 }
 
 func main() {
@@ -153,106 +347,119 @@ func main() {
 			return err
 		},
 		Action: func(ctx *cli.Context) error {
-			builder := postgres.Builder{
-				Action: func(ctx context.Context, index index.Index) error {
-					geocodingAPI := geocoding.NewClient()
-					weatherAPI := weather.NewClient()
+			action := func(ctx context.Context, index index.Index) error {
+				locations, err := cfg.locations()
+				if err != nil {
+					return err
+				}
 
-					geocodeResp, err := geocodingAPI.SearchByAddress(ctx, &cfg.Address)
-					if err != nil {
-						return err
-					}
+				mappings, err := datasets.GridpointMappings()
+				if err != nil {
+					return err
+				}
+
+				strategy, err := resample.ParseStrategy(cfg.Resample.Strategy)
+				if err != nil {
+					return err
+				}
+
+				if strategy == resample.Accumulate {
+					return fmt.Errorf("accumulate can only be used by columns that describe totals")
+				}
+
+				store, err := state.Open(cfg.State)
+				if err != nil {
+					return err
+				}
+
+				httpClient := transport.NewClient(cfg.HTTP)
+
+				geocodingAPI := geocoding.NewClient(httpClient)
+				geocodingAPI.Configure(cfg.Geocoding)
+
+				c := &collector{
+					cfg:          cfg,
+					mappings:     mappings,
+					strategy:     strategy,
+					store:        store,
+					cache:        &geo.Cache{Store: store, TTL: cfg.LocationTTL, Refresh: cfg.RefreshLocation},
+					geocodingAPI: geocodingAPI,
+					weatherAPI:   weather.NewClient(httpClient),
+				}
 
-					coordinates := geocodeResp.Result.AddressMatches[0].Coordinates
+				// locations that were retrieved are still indexed when others fail, the failure is reported afterwards
+				forecasts, collectErr := c.collectAll(ctx, locations)
 
-					point, err := weatherAPI.GetPoint(ctx, coordinates.Y, coordinates.X)
+				// locations are written first so that readings never refer to a location that has not been indexed
+				docs := make([]interface{}, 0)
+				for _, f := range forecasts {
+					docs = append(docs, f.location)
+				}
+
+				readings := 0
+				for _, f := range forecasts {
+					docs = append(docs, f.docs...)
+					readings += len(f.docs)
+				}
+
+				if len(docs) > 0 {
+					zaputil.Extract(ctx).Info("writing documents",
+						zap.Int("locations", len(forecasts)),
+						zap.Int("num", readings))
+
+					err = index.Index(ctx, docs...)
 					if err != nil {
 						return err
 					}
+				}
+
+				// resolved locations are saved even when none of the forecasts changed
+				if !cfg.DryRun {
+					for _, f := range forecasts {
+						if f.stateKey == "" {
+							continue
+						}
+
+						err = store.Set(f.stateKey, f.issuedAt)
+						if err != nil {
+							return err
+						}
+					}
 
-					gridpoints, err := weatherAPI.GetGridpoint(ctx, point.GridID, point.GridX, point.GridY)
+					err = store.Save()
 					if err != nil {
 						return err
 					}
+				}
+
+				if mem, ok := index.(*memory.Index); ok && cfg.DryRun {
+					for _, summary := range mem.Summarize() {
+						if summary.From.IsZero() {
+							fmt.Printf("would write %d %s documents\n", summary.Count, summary.Table)
+							continue
+						}
 
-					idx := make(map[int64]*Weather)
-
-					zaputil.Extract(ctx).Info("updating datapoints")
-					// the following block was code-generated from the following command
-					// cat ./internal/apis/weather/models.go | grep '*DataPoints' | awk '{print $1}' | xargs -I^ echo 'update(idx, gridpoints.^, func(w *Weather, v float64) { w.^ = v })' | pbcopy
-					update(idx, gridpoints.Temperature, func(w *Weather, v float64) { w.Temperature = v })
-					update(idx, gridpoints.Dewpoint, func(w *Weather, v float64) { w.Dewpoint = v })
-					update(idx, gridpoints.MaxTemperature, func(w *Weather, v float64) { w.MaxTemperature = v })
-					update(idx, gridpoints.MinTemperature, func(w *Weather, v float64) { w.MinTemperature = v })
-					update(idx, gridpoints.RelativeHumidity, func(w *Weather, v float64) { w.RelativeHumidity = v })
-					update(idx, gridpoints.ApparentTemperature, func(w *Weather, v float64) { w.ApparentTemperature = v })
-					update(idx, gridpoints.HeatIndex, func(w *Weather, v float64) { w.HeatIndex = v })
-					update(idx, gridpoints.WindChill, func(w *Weather, v float64) { w.WindChill = v })
-					update(idx, gridpoints.SkyCover, func(w *Weather, v float64) { w.SkyCover = v })
-					update(idx, gridpoints.WindDirection, func(w *Weather, v float64) { w.WindDirection = v })
-					update(idx, gridpoints.WindSpeed, func(w *Weather, v float64) { w.WindSpeed = v })
-					update(idx, gridpoints.WindGust, func(w *Weather, v float64) { w.WindGust = v })
-					update(idx, gridpoints.ProbabilityOfPrecipitation, func(w *Weather, v float64) { w.ProbabilityOfPrecipitation = v })
-					update(idx, gridpoints.QuantitativePrecipitation, func(w *Weather, v float64) { w.QuantitativePrecipitation = v })
-					update(idx, gridpoints.IceAccumulation, func(w *Weather, v float64) { w.IceAccumulation = v })
-					update(idx, gridpoints.SnowfallAmount, func(w *Weather, v float64) { w.SnowfallAmount = v })
-					update(idx, gridpoints.SnowLevel, func(w *Weather, v float64) { w.SnowLevel = v })
-					update(idx, gridpoints.CeilingHeight, func(w *Weather, v float64) { w.CeilingHeight = v })
-					update(idx, gridpoints.Visibility, func(w *Weather, v float64) { w.Visibility = v })
-					update(idx, gridpoints.TransportWindSpeed, func(w *Weather, v float64) { w.TransportWindSpeed = v })
-					update(idx, gridpoints.TransportWindDirection, func(w *Weather, v float64) { w.TransportWindDirection = v })
-					update(idx, gridpoints.MixingHeight, func(w *Weather, v float64) { w.MixingHeight = v })
-					update(idx, gridpoints.HainesIndex, func(w *Weather, v float64) { w.HainesIndex = v })
-					update(idx, gridpoints.LightningActivityLevel, func(w *Weather, v float64) { w.LightningActivityLevel = v })
-					update(idx, gridpoints.TwentyFootWindSpeed, func(w *Weather, v float64) { w.TwentyFootWindSpeed = v })
-					update(idx, gridpoints.TwentyFootWindDirection, func(w *Weather, v float64) { w.TwentyFootWindDirection = v })
-					update(idx, gridpoints.WaveHeight, func(w *Weather, v float64) { w.WaveHeight = v })
-					update(idx, gridpoints.WavePeriod, func(w *Weather, v float64) { w.WavePeriod = v })
-					update(idx, gridpoints.PrimarySwellHeight, func(w *Weather, v float64) { w.PrimarySwellHeight = v })
-					update(idx, gridpoints.PrimarySwellDirection, func(w *Weather, v float64) { w.PrimarySwellDirection = v })
-					update(idx, gridpoints.SecondarySwellHeight, func(w *Weather, v float64) { w.SecondarySwellHeight = v })
-					update(idx, gridpoints.SecondarySwellDirection, func(w *Weather, v float64) { w.SecondarySwellDirection = v })
-					update(idx, gridpoints.WavePeriod2, func(w *Weather, v float64) { w.WavePeriod2 = v })
-					update(idx, gridpoints.WindWaveHeight, func(w *Weather, v float64) { w.WindWaveHeight = v })
-					update(idx, gridpoints.DispersionIndex, func(w *Weather, v float64) { w.DispersionIndex = v })
-					update(idx, gridpoints.Pressure, func(w *Weather, v float64) { w.Pressure = v })
-					update(idx, gridpoints.ProbabilityOfTropicalStormWinds, func(w *Weather, v float64) { w.ProbabilityOfTropicalStormWinds = v })
-					update(idx, gridpoints.ProbabilityOfHurricaneWinds, func(w *Weather, v float64) { w.ProbabilityOfHurricaneWinds = v })
-					update(idx, gridpoints.PotentialOf15mphWinds, func(w *Weather, v float64) { w.PotentialOf15mphWinds = v })
-					update(idx, gridpoints.PotentialOf25mphWinds, func(w *Weather, v float64) { w.PotentialOf25mphWinds = v })
-					update(idx, gridpoints.PotentialOf35mphWinds, func(w *Weather, v float64) { w.PotentialOf35mphWinds = v })
-					update(idx, gridpoints.PotentialOf45mphWinds, func(w *Weather, v float64) { w.PotentialOf45mphWinds = v })
-					update(idx, gridpoints.PotentialOf20mphWindGusts, func(w *Weather, v float64) { w.PotentialOf20mphWindGusts = v })
-					update(idx, gridpoints.PotentialOf30mphWindGusts, func(w *Weather, v float64) { w.PotentialOf30mphWindGusts = v })
-					update(idx, gridpoints.PotentialOf40mphWindGusts, func(w *Weather, v float64) { w.PotentialOf40mphWindGusts = v })
-					update(idx, gridpoints.PotentialOf50mphWindGusts, func(w *Weather, v float64) { w.PotentialOf50mphWindGusts = v })
-					update(idx, gridpoints.PotentialOf60mphWindGusts, func(w *Weather, v float64) { w.PotentialOf60mphWindGusts = v })
-					update(idx, gridpoints.GrasslandFireDangerIndex, func(w *Weather, v float64) { w.GrasslandFireDangerIndex = v })
-					update(idx, gridpoints.ProbabilityOfThunder, func(w *Weather, v float64) { w.ProbabilityOfThunder = v })
-					update(idx, gridpoints.DavisStabilityIndex, func(w *Weather, v float64) { w.DavisStabilityIndex = v })
-					update(idx, gridpoints.AtmosphericDispersionIndex, func(w *Weather, v float64) { w.AtmosphericDispersionIndex = v })
-					update(idx, gridpoints.LowVisibilityOccurrenceRiskIndex, func(w *Weather, v float64) { w.LowVisibilityOccurrenceRiskIndex = v })
-					update(idx, gridpoints.Stability, func(w *Weather, v float64) { w.Stability = v })
-					update(idx, gridpoints.RedFlagThreatIndex, func(w *Weather, v float64) { w.RedFlagThreatIndex = v })
-
-					observedAt := clocks.Extract(ctx).Now()
-
-					docs := make([]interface{}, 0, len(idx))
-					for _, doc := range idx {
-						doc.ObservedAt = observedAt
-						doc.Elevation = float64(gridpoints.Elevation.Value)
-
-						docs = append(docs, doc)
+						fmt.Printf("would write %d %s documents from %s to %s\n", summary.Count, summary.Table,
+							summary.From.Format(time.RFC3339), summary.To.Format(time.RFC3339))
 					}
+				}
 
-					zaputil.Extract(ctx).Info("writing documents", zap.Int("num", len(docs)))
-					err = index.Index(docs...)
+				if collectErr != nil {
+					return collectErr
+				}
 
-					zaputil.Extract(ctx).Info("done")
-					return nil
-				},
+				zaputil.Extract(ctx).Info("done")
+				return nil
 			}
 
+			if cfg.DryRun {
+				cfg.Index.Endpoint = "memory://"
+				// avoid caching responses that a later run would then consider already indexed
+				cfg.HTTP.Cache.Directory = ""
+			}
+
+			builder := index.Builder{Action: action}
+
 			return builder.Run(ctx.Context, cfg.Index)
 		},
 		HideVersion:          true,
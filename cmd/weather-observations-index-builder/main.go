@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/apis/transport"
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/geo"
+	"github.com/mjpitz/homestead/internal/index"
+	_ "github.com/mjpitz/homestead/internal/index/backends"
+	"github.com/mjpitz/homestead/internal/index/memory"
+	"github.com/mjpitz/homestead/internal/state"
+	"github.com/mjpitz/homestead/internal/units"
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/config"
+	"github.com/mjpitz/myago/flagset"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+type Config struct {
+	ConfigFile      string            `json:"config_file"      usage:"specify the location of a file containing the configuration"`
+	Index           index.Config      `json:"index"`
+	Coordinates     string            `json:"coordinates"      usage:"latitude and longitude (e.g. 39.0473,-95.6752), geo: URI, or plus code used instead of the address"`
+	Address         geocoding.Address `json:"address"`
+	Geocoding       geocoding.Config  `json:"geocoding"`
+	Station         string            `json:"station"          usage:"index observations from this station (e.g. KTOP) instead of the one nearest the location"`
+	Lookback        time.Duration     `json:"lookback"         usage:"how far back to index observations when none have been indexed yet" default:"24h"`
+	HTTP            transport.Config  `json:"http"`
+	State           state.Config      `json:"state"`
+	LocationTTL     time.Duration     `json:"location_ttl"     usage:"how long geocoded addresses and nearby stations are reused from the state file (0 disables)" default:"720h"`
+	RefreshLocation bool              `json:"refresh_location" usage:"resolve the location again instead of reusing the state file" aliases:"refresh-location"`
+	Log             zaputil.Config    `json:"log"`
+	DryRun          bool              `json:"dry_run"          usage:"collect documents in memory and print a summary instead of writing them" aliases:"dry-run"`
+}
+
+// converter converts observed values into the unit of the column they are written to. The first error encountered is
+// retained and all later conversions are skipped.
+type converter struct {
+	err error
+}
+
+func (c *converter) value(q *weather.QuantitativeValue, unit units.Unit) (*float64, string) {
+	if q == nil || q.Value == nil || c.err != nil {
+		return nil, ""
+	}
+
+	value, err := units.ConvertCode(*q.Value, q.UnitCode, unit)
+	if err != nil {
+		c.err = err
+		return nil, ""
+	}
+
+	return &value, q.QualityControl
+}
+
+func convert(station string, observation *weather.ObservationProperties, observedAt time.Time) (*datasets.Observation, error) {
+	doc := &datasets.Observation{
+		Station:     station,
+		Timestamp:   observation.Timestamp,
+		ObservedAt:  observedAt,
+		Description: observation.TextDescription,
+	}
+
+	c := &converter{}
+
+	doc.Temperature, doc.TemperatureQC = c.value(observation.Temperature, units.Celsius)
+	doc.Dewpoint, doc.DewpointQC = c.value(observation.Dewpoint, units.Celsius)
+	doc.RelativeHumidity, doc.RelativeHumidityQC = c.value(observation.RelativeHumidity, units.Percent)
+	doc.WindDirection, doc.WindDirectionQC = c.value(observation.WindDirection, units.Degrees)
+	doc.WindSpeed, doc.WindSpeedQC = c.value(observation.WindSpeed, units.KilometersPerHour)
+	doc.WindGust, doc.WindGustQC = c.value(observation.WindGust, units.KilometersPerHour)
+	doc.BarometricPressure, doc.BarometricPressureQC = c.value(observation.BarometricPressure, units.Pascals)
+	doc.SeaLevelPressure, doc.SeaLevelPressureQC = c.value(observation.SeaLevelPressure, units.Pascals)
+	doc.Visibility, doc.VisibilityQC = c.value(observation.Visibility, units.Meters)
+	doc.MaxTemperatureLast24Hours, doc.MaxTemperatureLast24QC = c.value(observation.MaxTemperatureLast24Hours, units.Celsius)
+	doc.MinTemperatureLast24Hours, doc.MinTemperatureLast24QC = c.value(observation.MinTemperatureLast24Hours, units.Celsius)
+	doc.PrecipitationLastHour, doc.PrecipitationLastHourQC = c.value(observation.PrecipitationLastHour, units.Millimeters)
+	doc.PrecipitationLast3Hours, doc.PrecipitationLast3HoursQC = c.value(observation.PrecipitationLast3Hours, units.Millimeters)
+	doc.PrecipitationLast6Hours, doc.PrecipitationLast6HoursQC = c.value(observation.PrecipitationLast6Hours, units.Millimeters)
+	doc.WindChill, doc.WindChillQC = c.value(observation.WindChill, units.Celsius)
+	doc.HeatIndex, doc.HeatIndexQC = c.value(observation.HeatIndex, units.Celsius)
+
+	if c.err != nil {
+		return nil, fmt.Errorf("failed to convert observation from %s: %w", observation.Timestamp.Format(time.RFC3339), c.err)
+	}
+
+	return doc, nil
+}
+
+func main() {
+	cfg := &Config{}
+
+	app := &cli.App{
+		Name:      "weather-observations-index-builder",
+		Usage:     "Construct an index with conditions observed by a nearby weather station.",
+		UsageText: "weather-observations-index-builder [options]",
+		Flags:     flagset.Extract(cfg),
+		Before: func(ctx *cli.Context) (err error) {
+			ctx.Context = zaputil.Setup(ctx.Context, cfg.Log)
+
+			if cfg.ConfigFile != "" {
+				err := config.Load(ctx.Context, cfg, cfg.ConfigFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+		Action: func(ctx *cli.Context) error {
+			action := func(ctx context.Context, index index.Index) error {
+				store, err := state.Open(cfg.State)
+				if err != nil {
+					return err
+				}
+
+				httpClient := transport.NewClient(cfg.HTTP)
+				geocodingAPI := geocoding.NewClient(httpClient)
+				geocodingAPI.Configure(cfg.Geocoding)
+				weatherAPI := weather.NewClient(httpClient)
+
+				station := cfg.Station
+				if station == "" {
+					cache := &geo.Cache{Store: store, TTL: cfg.LocationTTL, Refresh: cfg.RefreshLocation}
+
+					place, err := cache.Locate(ctx, geocodingAPI, cfg.Coordinates, cfg.Address)
+					if err != nil {
+						return err
+					}
+
+					nearest := &weather.StationProperties{}
+					err = cache.Lookup(ctx, "locations/stations/"+place.String(), nearest, func() error {
+						stations, err := weatherAPI.GetStations(ctx, float32(place.Latitude), float32(place.Longitude))
+						if err != nil {
+							return err
+						}
+
+						if len(stations) == 0 {
+							return fmt.Errorf("no observation stations found near %.4f,%.4f", place.Latitude, place.Longitude)
+						}
+
+						*nearest = *stations[0]
+						return nil
+					})
+
+					if err != nil {
+						return err
+					}
+
+					station = nearest.StationIdentifier
+					zaputil.Extract(ctx).Info("using nearest station",
+						zap.String("station", station),
+						zap.String("name", nearest.Name))
+				}
+
+				now := clocks.Extract(ctx).Now()
+				stateKey := fmt.Sprintf("observations/%s/timestamp", station)
+
+				start := now.Add(-cfg.Lookback)
+				_, err = store.Get(stateKey, &start)
+				if err != nil {
+					return err
+				}
+
+				observations, err := weatherAPI.GetObservations(ctx, station, start, now)
+				if err != nil {
+					return err
+				}
+
+				latest := start
+				docs := make([]interface{}, 0, len(observations))
+				for _, observation := range observations {
+					doc, err := convert(station, observation, now)
+					if err != nil {
+						return err
+					}
+
+					docs = append(docs, doc)
+
+					if observation.Timestamp.After(latest) {
+						latest = observation.Timestamp
+					}
+				}
+
+				zaputil.Extract(ctx).Info("writing documents", zap.Int("num", len(docs)))
+				err = index.Index(ctx, docs...)
+				if err != nil {
+					return err
+				}
+
+				if !cfg.DryRun {
+					err = store.Set(stateKey, latest)
+					if err == nil {
+						err = store.Save()
+					}
+
+					if err != nil {
+						return err
+					}
+				}
+
+				if mem, ok := index.(*memory.Index); ok && cfg.DryRun {
+					for _, summary := range mem.Summarize() {
+						fmt.Printf("would write %d %s documents from %s to %s\n", summary.Count, summary.Table,
+							summary.From.Format(time.RFC3339), summary.To.Format(time.RFC3339))
+					}
+				}
+
+				zaputil.Extract(ctx).Info("done")
+				return nil
+			}
+
+			if cfg.DryRun {
+				cfg.Index.Endpoint = "memory://"
+				cfg.HTTP.Cache.Directory = ""
+			}
+
+			builder := index.Builder{Action: action}
+
+			return builder.Run(ctx.Context, cfg.Index)
+		},
+		HideVersion:          true,
+		HideHelpCommand:      true,
+		EnableBashCompletion: true,
+		BashComplete:         cli.DefaultAppComplete,
+		Metadata: map[string]interface{}{
+			"arch":       runtime.GOARCH,
+			"go_version": strings.TrimPrefix(runtime.Version(), "go"),
+			"os":         runtime.GOOS,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -12,12 +12,19 @@ require (
 require github.com/spf13/afero v1.8.0 // indirect
 
 require (
+	github.com/fraugster/parquet-go v0.12.0
+	github.com/golang/snappy v0.0.4
+	github.com/jonboulle/clockwork v0.2.2
 	go.uber.org/zap v1.20.0
+	golang.org/x/time v0.0.0-20211116232009-f0f3c7e86c11
+	google.golang.org/protobuf v1.27.1
 	gorm.io/driver/postgres v1.2.3
+	gorm.io/driver/sqlite v1.2.6
 	gorm.io/gorm v1.22.5
 )
 
 require (
+	github.com/apache/thrift v0.16.0 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/jackc/chunkreader/v2 v2.0.1 // indirect
@@ -30,8 +37,8 @@ require (
 	github.com/jackc/pgx/v4 v4.14.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.4 // indirect
-	github.com/jonboulle/clockwork v0.2.2 // indirect
 	github.com/kr/pretty v0.3.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.9 // indirect
 	github.com/pelletier/go-toml v1.9.4 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -0,0 +1,212 @@
+// Package accuracy scores past forecasts against the conditions that were later observed.
+package accuracy
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+)
+
+type Config struct {
+	LeadTimeResolution time.Duration `json:"lead_time_resolution" usage:"the width of each lead time bucket scores are grouped into" default:"6h"`
+	Resolution         time.Duration `json:"resolution"           usage:"the spacing between forecast readings, used to pair them with observations" default:"15m"`
+}
+
+// Field pairs a forecast column with the observed value it predicts.
+type Field struct {
+	// Name is the json name of the forecast column.
+	Name     string
+	Forecast func(w *datasets.Weather) float64
+	// Observed returns the observed value and its quality control flag.
+	Observed func(o *datasets.Observation) (*float64, string)
+	// Probability is set for percentages describing the chance an observed value is non-zero. These are scored using
+	// probabilities in the range [0, 1] and additionally receive a Brier score.
+	Probability bool
+}
+
+// Fields lists the forecast columns that can be scored against station observations.
+var Fields = []Field{
+	{
+		Name:     "temperature_degc",
+		Forecast: func(w *datasets.Weather) float64 { return w.Temperature },
+		Observed: func(o *datasets.Observation) (*float64, string) { return o.Temperature, o.TemperatureQC },
+	},
+	{
+		Name:     "dewpoint_degc",
+		Forecast: func(w *datasets.Weather) float64 { return w.Dewpoint },
+		Observed: func(o *datasets.Observation) (*float64, string) { return o.Dewpoint, o.DewpointQC },
+	},
+	{
+		Name:     "relative_humidity_pct",
+		Forecast: func(w *datasets.Weather) float64 { return w.RelativeHumidity },
+		Observed: func(o *datasets.Observation) (*float64, string) { return o.RelativeHumidity, o.RelativeHumidityQC },
+	},
+	{
+		Name:     "wind_speed_kph",
+		Forecast: func(w *datasets.Weather) float64 { return w.WindSpeed },
+		Observed: func(o *datasets.Observation) (*float64, string) { return o.WindSpeed, o.WindSpeedQC },
+	},
+	{
+		Name:     "precipitation_probability_pct",
+		Forecast: func(w *datasets.Weather) float64 { return w.ProbabilityOfPrecipitation },
+		Observed: func(o *datasets.Observation) (*float64, string) {
+			return o.PrecipitationLastHour, o.PrecipitationLastHourQC
+		},
+		Probability: true,
+	},
+}
+
+// rejected reports whether the quality control flag marks the observed value as unusable.
+func rejected(qc string) bool {
+	return qc == weather.QualityControlRejected || qc == weather.QualityControlBad
+}
+
+// issuedAt returns when the forecast was issued, falling back to when it was retrieved for readings indexed before the
+// issue time was recorded.
+func issuedAt(w *datasets.Weather) time.Time {
+	if !w.IssuedAt.IsZero() {
+		return w.IssuedAt
+	}
+
+	return w.ObservedAt
+}
+
+type key struct {
+	date     time.Time
+	station  string
+	field    string
+	leadTime time.Duration
+}
+
+type sums struct {
+	count    int
+	absolute float64
+	error    float64
+	squared  float64
+}
+
+// Evaluate pairs every observation with the forecasts issued for the same time and scores each field, grouped by the
+// day of the observation, station, and lead time. Forecasts retrieved more than once for the same issue time are only
+// counted once.
+func Evaluate(cfg Config, forecasts []*datasets.Weather, observations []*datasets.Observation, computedAt time.Time) []*datasets.ForecastAccuracy {
+	if cfg.Resolution <= 0 {
+		cfg.Resolution = 15 * time.Minute
+	}
+
+	if cfg.LeadTimeResolution <= 0 {
+		cfg.LeadTimeResolution = 6 * time.Hour
+	}
+
+	type revision struct {
+		location  string
+		timestamp int64
+		issuedAt  int64
+	}
+
+	seen := make(map[revision]bool, len(forecasts))
+	byTime := make(map[int64][]*datasets.Weather)
+
+	for _, forecast := range forecasts {
+		timestamp := forecast.Timestamp.Truncate(cfg.Resolution).Unix()
+		rev := revision{forecast.Location, timestamp, issuedAt(forecast).Unix()}
+
+		if seen[rev] {
+			continue
+		}
+
+		seen[rev] = true
+		byTime[timestamp] = append(byTime[timestamp], forecast)
+	}
+
+	totals := make(map[key]*sums)
+
+	for _, observation := range observations {
+		t := observation.Timestamp.UTC()
+		date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+		for _, forecast := range byTime[t.Truncate(cfg.Resolution).Unix()] {
+			leadTime := forecast.Timestamp.Sub(issuedAt(forecast))
+			if leadTime < 0 {
+				continue
+			}
+
+			for _, field := range Fields {
+				observed, qc := field.Observed(observation)
+				if observed == nil || rejected(qc) {
+					continue
+				}
+
+				predicted, actual := field.Forecast(forecast), *observed
+				if field.Probability {
+					predicted, actual = predicted/100, 0
+					if *observed > 0 {
+						actual = 1
+					}
+				}
+
+				k := key{date, observation.Station, field.Name, leadTime.Truncate(cfg.LeadTimeResolution)}
+				if totals[k] == nil {
+					totals[k] = &sums{}
+				}
+
+				diff := predicted - actual
+				if diff < 0 {
+					totals[k].absolute -= diff
+				} else {
+					totals[k].absolute += diff
+				}
+
+				totals[k].count++
+				totals[k].error += diff
+				totals[k].squared += diff * diff
+			}
+		}
+	}
+
+	probability := make(map[string]bool, len(Fields))
+	for _, field := range Fields {
+		probability[field.Name] = field.Probability
+	}
+
+	results := make([]*datasets.ForecastAccuracy, 0, len(totals))
+	for k, s := range totals {
+		n := float64(s.count)
+
+		result := &datasets.ForecastAccuracy{
+			Date:       k.date,
+			Station:    k.station,
+			Field:      k.field,
+			LeadTime:   int(k.leadTime / time.Hour),
+			ComputedAt: computedAt,
+			Count:      s.count,
+			MAE:        s.absolute / n,
+			Bias:       s.error / n,
+		}
+
+		if probability[k.field] {
+			brier := s.squared / n
+			result.Brier = &brier
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+
+		switch {
+		case !a.Date.Equal(b.Date):
+			return a.Date.Before(b.Date)
+		case a.Station != b.Station:
+			return a.Station < b.Station
+		case a.Field != b.Field:
+			return a.Field < b.Field
+		}
+
+		return a.LeadTime < b.LeadTime
+	})
+
+	return results
+}
@@ -0,0 +1,87 @@
+package accuracy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/accuracy"
+	"github.com/mjpitz/homestead/internal/datasets"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestEvaluate(t *testing.T) {
+	date := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+	target := date.Add(15 * time.Hour)
+
+	// issued 3 and 27 hours before the target time
+	recent := date.Add(12 * time.Hour)
+	older := date.Add(-12 * time.Hour)
+
+	forecasts := []*datasets.Weather{
+		{Timestamp: target, IssuedAt: recent, ObservedAt: recent, Temperature: 2, ProbabilityOfPrecipitation: 80},
+		// retrieved a second time without a new forecast being issued
+		{Timestamp: target, IssuedAt: recent, ObservedAt: recent.Add(time.Hour), Temperature: 2, ProbabilityOfPrecipitation: 80},
+		{Timestamp: target, IssuedAt: older, ObservedAt: older, Temperature: -1, ProbabilityOfPrecipitation: 30},
+		// issued after the time it describes
+		{Timestamp: target, IssuedAt: target.Add(time.Hour), Temperature: 100},
+	}
+
+	observations := []*datasets.Observation{
+		{
+			Station:                 "KTOP",
+			Timestamp:               target.Add(5 * time.Minute),
+			Temperature:             float(1),
+			TemperatureQC:           "V",
+			Dewpoint:                float(-40),
+			DewpointQC:              "X",
+			PrecipitationLastHour:   float(0.5),
+			PrecipitationLastHourQC: "V",
+		},
+	}
+
+	computedAt := date.Add(48 * time.Hour)
+
+	results := accuracy.Evaluate(accuracy.Config{
+		LeadTimeResolution: 6 * time.Hour,
+		Resolution:         15 * time.Minute,
+	}, forecasts, observations, computedAt)
+
+	expected := []struct {
+		field    string
+		leadTime int
+		mae      float64
+		bias     float64
+		brier    float64
+	}{
+		{"precipitation_probability_pct", 0, 0.2, -0.2, 0.04},
+		{"precipitation_probability_pct", 24, 0.7, -0.7, 0.49},
+		{"temperature_degc", 0, 1, 1, 0},
+		{"temperature_degc", 24, 2, -2, 0},
+	}
+
+	require.Len(t, results, len(expected))
+
+	for i, result := range results {
+		require.Equal(t, date, result.Date)
+		require.Equal(t, "KTOP", result.Station)
+		require.Equal(t, computedAt, result.ComputedAt)
+		require.Equal(t, 1, result.Count)
+
+		require.Equal(t, expected[i].field, result.Field)
+		require.Equal(t, expected[i].leadTime, result.LeadTime)
+		require.InDelta(t, expected[i].mae, result.MAE, 1e-9)
+		require.InDelta(t, expected[i].bias, result.Bias, 1e-9)
+
+		if expected[i].field == "precipitation_probability_pct" {
+			require.NotNil(t, result.Brier)
+			require.InDelta(t, expected[i].brier, *result.Brier, 1e-9)
+		} else {
+			require.Nil(t, result.Brier)
+		}
+	}
+}
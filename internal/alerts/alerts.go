@@ -0,0 +1,114 @@
+// Package alerts converts active weather alerts into documents and tracks the alerts they update or cancel.
+package alerts
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+)
+
+// Convert turns an alert returned by weather.gov into a document that was last seen at observedAt.
+func Convert(alert *weather.Alert, observedAt time.Time) (*datasets.Alert, error) {
+	parameters, err := json.Marshal(alert.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	references := make([]string, 0, len(alert.References))
+	for _, reference := range alert.References {
+		references = append(references, reference.Identifier)
+	}
+
+	return &datasets.Alert{
+		ID:          alert.ID,
+		Sent:        alert.Sent,
+		Effective:   alert.Effective,
+		Onset:       alert.Onset,
+		Expires:     alert.Expires,
+		Ends:        alert.Ends,
+		ObservedAt:  observedAt,
+		Status:      alert.Status,
+		MessageType: alert.MessageType,
+		Category:    alert.Category,
+		Event:       alert.Event,
+		Severity:    alert.Severity,
+		Certainty:   alert.Certainty,
+		Urgency:     alert.Urgency,
+		Sender:      alert.Sender,
+		SenderName:  alert.SenderName,
+		Headline:    alert.Headline,
+		Description: alert.Description,
+		Instruction: alert.Instruction,
+		Response:    alert.Response,
+		AreaDesc:    alert.AreaDesc,
+		Zones:       strings.Join(alert.AffectedZones, ","),
+		Parameters:  string(parameters),
+		References:  strings.Join(references, ","),
+	}, nil
+}
+
+// expired reports whether the alert no longer applies, using the later of its end and expiration times.
+func expired(alert *datasets.Alert, now time.Time) bool {
+	expires := alert.Ends
+	if expires.IsZero() || alert.Expires.After(expires) {
+		expires = alert.Expires
+	}
+
+	return !expires.After(now)
+}
+
+// Track converts the active alerts into documents. Alerts referenced by an update or cancellation are marked as
+// superseded when they are either still active or were seen by a previous run, so references to alerts that are no
+// longer active can only be recorded when previous is carried between runs. The returned documents are sorted by ID,
+// and the returned map contains every alert that has not yet expired so later runs can mark them as well.
+func Track(previous map[string]*datasets.Alert, active []*weather.Alert, now time.Time) ([]*datasets.Alert, map[string]*datasets.Alert, error) {
+	current := make(map[string]*datasets.Alert, len(previous)+len(active))
+	for id, alert := range previous {
+		if !expired(alert, now) {
+			current[id] = alert
+		}
+	}
+
+	changed := make(map[string]*datasets.Alert, len(active))
+	for _, alert := range active {
+		doc, err := Convert(alert, now)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// preserve any knowledge of this alert being superseded in a previous run
+		if last, ok := current[doc.ID]; ok {
+			doc.SupersededBy = last.SupersededBy
+			doc.Cancelled = last.Cancelled
+		}
+
+		current[doc.ID] = doc
+		changed[doc.ID] = doc
+	}
+
+	for _, alert := range active {
+		for _, reference := range alert.References {
+			doc, ok := current[reference.Identifier]
+			if !ok || doc.ID == alert.ID {
+				continue
+			}
+
+			doc.SupersededBy = alert.ID
+			doc.Cancelled = alert.MessageType == weather.MessageTypeCancel
+			changed[doc.ID] = doc
+		}
+	}
+
+	docs := make([]*datasets.Alert, 0, len(changed))
+	for _, doc := range changed {
+		docs = append(docs, doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+
+	return docs, current, nil
+}
@@ -0,0 +1,136 @@
+package alerts_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/alerts"
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+)
+
+var now = time.Date(2022, 1, 12, 12, 0, 0, 0, time.UTC)
+
+func alert(id, messageType string, references ...string) *weather.Alert {
+	a := &weather.Alert{
+		ID:          id,
+		Sent:        now.Add(-time.Hour),
+		Expires:     now.Add(time.Hour),
+		MessageType: messageType,
+		Event:       "Winter Storm Warning",
+	}
+
+	for _, reference := range references {
+		a.References = append(a.References, &weather.AlertReference{Identifier: reference})
+	}
+
+	return a
+}
+
+func TestTrack(t *testing.T) {
+	type result struct {
+		ID           string
+		SupersededBy string
+		Cancelled    bool
+	}
+
+	testCases := []struct {
+		name     string
+		previous map[string]*datasets.Alert
+		active   []*weather.Alert
+		docs     []result
+		current  []string
+	}{
+		{
+			name:    "new",
+			active:  []*weather.Alert{alert("a", weather.MessageTypeAlert)},
+			docs:    []result{{ID: "a"}},
+			current: []string{"a"},
+		},
+		{
+			name: "updated while active",
+			active: []*weather.Alert{
+				alert("a", weather.MessageTypeAlert),
+				alert("b", weather.MessageTypeUpdate, "a"),
+			},
+			docs:    []result{{ID: "a", SupersededBy: "b"}, {ID: "b"}},
+			current: []string{"a", "b"},
+		},
+		{
+			name:     "updated after a previous run",
+			previous: map[string]*datasets.Alert{"a": {ID: "a", Expires: now.Add(time.Hour)}},
+			active:   []*weather.Alert{alert("b", weather.MessageTypeUpdate, "a")},
+			docs:     []result{{ID: "a", SupersededBy: "b"}, {ID: "b"}},
+			current:  []string{"a", "b"},
+		},
+		{
+			name:     "cancelled",
+			previous: map[string]*datasets.Alert{"a": {ID: "a", Expires: now.Add(time.Hour)}},
+			active:   []*weather.Alert{alert("b", weather.MessageTypeCancel, "a")},
+			docs:     []result{{ID: "a", SupersededBy: "b", Cancelled: true}, {ID: "b"}},
+			current:  []string{"a", "b"},
+		},
+		{
+			name: "superseded in a previous run",
+			previous: map[string]*datasets.Alert{
+				"a": {ID: "a", Expires: now.Add(time.Hour), SupersededBy: "b", Cancelled: true},
+			},
+			active:  []*weather.Alert{alert("a", weather.MessageTypeAlert)},
+			docs:    []result{{ID: "a", SupersededBy: "b", Cancelled: true}},
+			current: []string{"a"},
+		},
+		{
+			name: "expired",
+			previous: map[string]*datasets.Alert{
+				"a": {ID: "a", Expires: now.Add(-2 * time.Hour), Ends: now.Add(-time.Hour)},
+				"b": {ID: "b", Expires: now.Add(-time.Hour), Ends: now.Add(time.Hour)},
+			},
+			active:  []*weather.Alert{alert("c", weather.MessageTypeUpdate, "a")},
+			docs:    []result{{ID: "c"}},
+			current: []string{"b", "c"},
+		},
+		{
+			name:    "reference without state",
+			active:  []*weather.Alert{alert("b", weather.MessageTypeCancel, "a")},
+			docs:    []result{{ID: "b"}},
+			current: []string{"b"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			docs, current, err := alerts.Track(testCase.previous, testCase.active, now)
+			require.NoError(t, err)
+
+			results := make([]result, 0, len(docs))
+			for _, doc := range docs {
+				results = append(results, result{ID: doc.ID, SupersededBy: doc.SupersededBy, Cancelled: doc.Cancelled})
+			}
+
+			require.Equal(t, testCase.docs, results)
+
+			ids := make([]string, 0, len(current))
+			for id := range current {
+				ids = append(ids, id)
+			}
+
+			require.ElementsMatch(t, testCase.current, ids)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	a := alert("b", weather.MessageTypeUpdate, "a")
+	a.AffectedZones = []string{"KSZ040", "KSZ041"}
+	a.Parameters = map[string][]string{"NWSheadline": {"WINTER STORM WARNING"}}
+
+	doc, err := alerts.Convert(a, now)
+	require.NoError(t, err)
+	require.Equal(t, "b", doc.ID)
+	require.Equal(t, now, doc.ObservedAt)
+	require.Equal(t, "KSZ040,KSZ041", doc.Zones)
+	require.Equal(t, "a", doc.References)
+	require.Equal(t, `{"NWSheadline":["WINTER STORM WARNING"]}`, doc.Parameters)
+}
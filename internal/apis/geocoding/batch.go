@@ -0,0 +1,269 @@
+package geocoding
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
+)
+
+const (
+	// MaxBatchSize is the largest number of addresses the geocoder accepts in a single upload.
+	MaxBatchSize = 10000
+	// DefaultBatchSize is the number of addresses sent in each upload unless configured otherwise. Smaller uploads
+	// finish well within the timeout and lose less work when one has to be retried.
+	DefaultBatchSize = 1000
+	// DefaultBatchTimeout is how long a single upload may take unless configured otherwise.
+	DefaultBatchTimeout = 10 * time.Minute
+)
+
+const (
+	// MatchFound indicates the address matched a single location.
+	MatchFound = "Match"
+	// MatchNotFound indicates the address did not match any location.
+	MatchNotFound = "No_Match"
+	// MatchTie indicates the address matched more than one location.
+	MatchTie = "Tie"
+)
+
+// BatchAddress is an address to geocode along with an ID used to identify its result.
+type BatchAddress struct {
+	ID string
+	Address
+}
+
+// BatchResult describes the outcome of geocoding a single BatchAddress. Coordinates and TigerLine are only set when
+// the address was matched, and FIPS is only set when searching geographies.
+type BatchResult struct {
+	ID             string
+	InputAddress   string
+	Match          string // see Match* constants
+	Exactness      string // Exact or Non_Exact
+	MatchedAddress string
+	Coordinates    *Coordinates
+	TigerLine      *TigerLine
+	FIPS           *FIPS
+}
+
+// SearchByAddressBatch geocodes many addresses at once by uploading them as CSV files of up to BatchSize addresses.
+// Results are returned in the same order as the addresses, and every address must have a unique ID.
+func (c *Client) SearchByAddressBatch(ctx context.Context, addresses []*BatchAddress) ([]*BatchResult, error) {
+	return c.batch(ctx, "/locations/addressbatch", false, addresses)
+}
+
+// SearchGeographiesByAddressBatch behaves like SearchByAddressBatch, but also returns the FIPS codes of the
+// geographies containing each match.
+func (c *Client) SearchGeographiesByAddressBatch(ctx context.Context, addresses []*BatchAddress) ([]*BatchResult, error) {
+	return c.batch(ctx, "/geographies/addressbatch", true, addresses)
+}
+
+func (c *Client) batch(ctx context.Context, path string, geographies bool, addresses []*BatchAddress) ([]*BatchResult, error) {
+	order := make(map[string]int, len(addresses))
+	for i, address := range addresses {
+		if _, ok := order[address.ID]; ok {
+			return nil, fmt.Errorf("duplicate batch address id: %q", address.ID)
+		}
+
+		order[address.ID] = i
+	}
+
+	size := c.BatchSize
+	if size <= 0 || size > MaxBatchSize {
+		size = MaxBatchSize
+	}
+
+	results := make([]*BatchResult, len(addresses))
+
+	for start := 0; start < len(addresses); start += size {
+		end := start + size
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		batch, err := c.upload(ctx, path, geographies, addresses[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range batch {
+			i, ok := order[result.ID]
+			if !ok {
+				return nil, fmt.Errorf("unexpected batch result id: %q", result.ID)
+			}
+
+			results[i] = result
+		}
+	}
+
+	for i, result := range results {
+		if result == nil {
+			return nil, fmt.Errorf("missing batch result for id: %q", addresses[i].ID)
+		}
+	}
+
+	return results, nil
+}
+
+func (c *Client) upload(ctx context.Context, path string, geographies bool, addresses []*BatchAddress) ([]*BatchResult, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+
+	fields := [][2]string{{"benchmark", c.Benchmark}}
+	if geographies {
+		fields = append(fields, [2]string{"vintage", c.Vintage})
+	}
+
+	for _, field := range fields {
+		err := form.WriteField(field[0], field[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := form.CreateFormFile("addressFile", "addresses.csv")
+	if err != nil {
+		return nil, err
+	}
+
+	err = WriteBatch(file, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	err = form.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// uploads take far longer than the per-attempt timeout used for other requests
+	ctx = transport.WithTimeout(ctx, c.BatchTimeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return ReadBatchResults(resp.Body)
+}
+
+// WriteBatch writes the addresses in the CSV format expected by the geocoder: ID, street, city, state, and zip.
+func WriteBatch(w io.Writer, addresses []*BatchAddress) error {
+	writer := csv.NewWriter(w)
+
+	for _, address := range addresses {
+		err := writer.Write([]string{address.ID, address.Street, address.City, address.State, address.Zip})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadBatch reads addresses from a CSV file in the format accepted by the geocoder: ID, street, city, state, and zip.
+func ReadBatch(r io.Reader) ([]*BatchAddress, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 5
+	reader.TrimLeadingSpace = true
+
+	addresses := make([]*BatchAddress, 0)
+	for {
+		record, err := reader.Read()
+		switch {
+		case err == io.EOF:
+			return addresses, nil
+		case err != nil:
+			return nil, err
+		}
+
+		addresses = append(addresses, &BatchAddress{
+			ID: record[0],
+			Address: Address{
+				Street: record[1],
+				City:   record[2],
+				State:  record[3],
+				Zip:    record[4],
+			},
+		})
+	}
+}
+
+// ReadBatchResults parses the CSV returned by a batch upload. Matched rows contain the matched address, coordinates
+// ("longitude,latitude"), TIGER/Line ID and side, followed by the state, county, tract, and block codes when
+// geographies were requested. Unmatched rows only contain the ID, input address, and match status.
+func ReadBatchResults(r io.Reader) ([]*BatchResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	results := make([]*BatchResult, 0)
+	for {
+		record, err := reader.Read()
+		switch {
+		case err == io.EOF:
+			return results, nil
+		case err != nil:
+			return nil, err
+		case len(record) < 3:
+			return nil, fmt.Errorf("unexpected batch result: %q", strings.Join(record, ","))
+		}
+
+		result := &BatchResult{
+			ID:           record[0],
+			InputAddress: record[1],
+			Match:        record[2],
+		}
+
+		if len(record) >= 8 && result.Match == MatchFound {
+			result.Exactness = record[3]
+			result.MatchedAddress = record[4]
+			result.TigerLine = &TigerLine{ID: record[6], Side: record[7]}
+
+			coordinates := strings.Split(record[5], ",")
+			if len(coordinates) != 2 {
+				return nil, fmt.Errorf("unexpected coordinates for %q: %q", result.ID, record[5])
+			}
+
+			x, err := strconv.ParseFloat(coordinates[0], 32)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected longitude for %q: %w", result.ID, err)
+			}
+
+			y, err := strconv.ParseFloat(coordinates[1], 32)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected latitude for %q: %w", result.ID, err)
+			}
+
+			result.Coordinates = &Coordinates{X: float32(x), Y: float32(y)}
+		}
+
+		if len(record) >= 12 && result.Match == MatchFound {
+			state, county, tract, block := record[8], record[9], record[10], record[11]
+
+			result.FIPS = &FIPS{
+				State:  state,
+				County: state + county,
+				Tract:  state + county + tract,
+				Block:  state + county + tract + block,
+			}
+		}
+
+		results = append(results, result)
+	}
+}
@@ -0,0 +1,117 @@
+package geocoding_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/apis/transport"
+)
+
+func TestSearchGeographiesByAddressBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/geographies/addressbatch", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		require.Equal(t, "Public_AR_Current", r.FormValue("benchmark"))
+		require.Equal(t, "Current_Current", r.FormValue("vintage"))
+
+		file, _, err := r.FormFile("addressFile")
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		require.Equal(t, "house,1 Main St,Topeka,KS,66603\nfarm,\"12 Rural Rte, Lot 4\",Rossville,KS,\n", string(data))
+
+		// results are not returned in the order they were uploaded
+		_, _ = w.Write([]byte(`"farm","12 Rural Rte, Lot 4, Rossville, KS, ","No_Match"
+"house","1 Main St, Topeka, KS, 66603","Match","Exact","1 MAIN ST, TOPEKA, KS, 66603","-95.6752,39.0473","636990150","L","20","177","000800","2004"
+`))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	results, err := client.SearchGeographiesByAddressBatch(context.Background(), []*geocoding.BatchAddress{
+		{ID: "house", Address: geocoding.Address{Street: "1 Main St", City: "Topeka", State: "KS", Zip: "66603"}},
+		{ID: "farm", Address: geocoding.Address{Street: "12 Rural Rte, Lot 4", City: "Rossville", State: "KS"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	house := results[0]
+	require.Equal(t, "house", house.ID)
+	require.Equal(t, geocoding.MatchFound, house.Match)
+	require.Equal(t, "1 MAIN ST, TOPEKA, KS, 66603", house.MatchedAddress)
+	require.InDelta(t, 39.0473, house.Coordinates.Y, 0.0001)
+	require.InDelta(t, -95.6752, house.Coordinates.X, 0.0001)
+	require.Equal(t, "636990150", house.TigerLine.ID)
+	require.Equal(t, "201770008002004", house.FIPS.Block)
+
+	farm := results[1]
+	require.Equal(t, "farm", farm.ID)
+	require.Equal(t, geocoding.MatchNotFound, farm.Match)
+	require.Nil(t, farm.Coordinates)
+	require.Nil(t, farm.FIPS)
+}
+
+func TestSearchByAddressBatchChunks(t *testing.T) {
+	uploads := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&uploads, 1)
+
+		file, _, err := r.FormFile("addressFile")
+		require.NoError(t, err)
+
+		addresses, err := geocoding.ReadBatch(file)
+		require.NoError(t, err)
+		require.Len(t, addresses, 1)
+
+		// uploads routinely outlast the per-attempt timeout used for other requests
+		time.Sleep(100 * time.Millisecond)
+
+		_, _ = w.Write([]byte(`"` + addresses[0].ID + `","","No_Match"` + "\n"))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(transport.NewClient(transport.Config{
+		Timeout: 20 * time.Millisecond,
+		Retry:   transport.RetryConfig{MaxAttempts: 1},
+	}))
+	client.BaseURL = server.URL
+	client.Configure(geocoding.Config{BatchSize: 1})
+
+	results, err := client.SearchByAddressBatch(context.Background(), []*geocoding.BatchAddress{{ID: "1"}, {ID: "2"}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, "2", results[1].ID)
+	require.Equal(t, int32(2), atomic.LoadInt32(&uploads))
+}
+
+func TestSearchByAddressBatchDuplicateID(t *testing.T) {
+	client := geocoding.NewClient(nil)
+
+	_, err := client.SearchByAddressBatch(context.Background(), []*geocoding.BatchAddress{{ID: "1"}, {ID: "1"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "duplicate")
+}
+
+func TestReadBatch(t *testing.T) {
+	addresses, err := geocoding.ReadBatch(strings.NewReader("1, 1 Main St, Topeka, KS, 66603\n"))
+	require.NoError(t, err)
+	require.Len(t, addresses, 1)
+	require.Equal(t, "Topeka", addresses[0].City)
+
+	_, err = geocoding.ReadBatch(strings.NewReader("1, 1 Main St, Topeka\n"))
+	require.Error(t, err)
+}
@@ -3,54 +3,253 @@ package geocoding
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
 )
 
 const (
 	defaultBaseURL   = "https://geocoding.geo.census.gov/geocoder"
-	defaultBenchmark = "4"
+	defaultBenchmark = "Public_AR_Current"
+	defaultVintage   = "Current_Current"
 )
 
-func NewClient() *Client {
+// Config selects the data used by the geocoder. The benchmark determines the version of the address ranges that are
+// searched, while the vintage determines the version of the census geographies returned for a location. Both accept
+// an ID or a name, as listed by GetBenchmarks and GetVintages. Batch uploads are split into chunks of BatchSize
+// addresses, each of which may take up to BatchTimeout.
+type Config struct {
+	Benchmark    string        `json:"benchmark"     usage:"the version of the address data used by the census geocoder" default:"Public_AR_Current"`
+	Vintage      string        `json:"vintage"       usage:"the version of the census geographies returned by the geocoder" default:"Current_Current"`
+	BatchSize    int           `json:"batch_size"    usage:"the number of addresses sent in each batch upload (at most 10000)" default:"1000"`
+	BatchTimeout time.Duration `json:"batch_timeout" usage:"how long a single batch upload may take before it is abandoned" default:"10m"`
+}
+
+// NewClient constructs a Client that issues requests using the provided http.Client. Passing a client created by
+// transport.NewClient ensures that transient failures are retried.
+func NewClient(client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
 	return &Client{
-		BaseURL: defaultBaseURL,
+		BaseURL:      defaultBaseURL,
+		HTTPClient:   client,
+		Benchmark:    defaultBenchmark,
+		Vintage:      defaultVintage,
+		BatchSize:    DefaultBatchSize,
+		BatchTimeout: DefaultBatchTimeout,
 	}
 }
 
 type Client struct {
-	BaseURL string
+	BaseURL    string
+	HTTPClient *http.Client
+	Benchmark  string
+	Vintage    string
+	// BatchSize is the number of addresses sent in each batch upload.
+	BatchSize int
+	// BatchTimeout replaces the per-attempt timeout of the http.Client for batch uploads, which take far longer than
+	// other requests. Zero disables the per-attempt timeout for uploads.
+	BatchTimeout time.Duration
 }
 
+// Configure selects the benchmark, vintage, and batch settings used by later requests. Empty values leave the current
+// selection.
+func (c *Client) Configure(cfg Config) {
+	if cfg.Benchmark != "" {
+		c.Benchmark = cfg.Benchmark
+	}
+
+	if cfg.Vintage != "" {
+		c.Vintage = cfg.Vintage
+	}
+
+	if cfg.BatchSize > 0 {
+		c.BatchSize = cfg.BatchSize
+	}
+
+	if cfg.BatchTimeout > 0 {
+		c.BatchTimeout = cfg.BatchTimeout
+	}
+}
+
+// param formats a single query parameter.
+func param(key, value string) string {
+	return key + "=" + url.QueryEscape(value)
+}
+
+// get issues a GET request for the path and decodes the response into result. Parameters are sent in the order they
+// are provided since the API is sensitive to it.
+func (c *Client) get(ctx context.Context, path string, params []string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.URL.RawQuery = strings.Join(params, "&")
+
+	return c.do(req, result)
+}
+
+// send issues the request, returning an error for any response without a 2xx status code. Error messages returned by
+// the API are decoded into an *Error.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = transport.CheckResponse(resp)
+	if err != nil {
+		_ = resp.Body.Close()
+
+		statusErr := &transport.StatusError{}
+		if errors.As(err, &statusErr) {
+			apiErr := &Error{}
+			if json.Unmarshal(statusErr.Body, apiErr) == nil && len(apiErr.Errors) > 0 {
+				return nil, apiErr
+			}
+		}
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// do issues the request and decodes the response into result.
+func (c *Client) do(req *http.Request, result interface{}) error {
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// GetBenchmarks lists the benchmarks that can be searched.
+func (c *Client) GetBenchmarks(ctx context.Context) ([]*Benchmark, error) {
+	result := &BenchmarksResponse{}
+
+	err := c.get(ctx, "/benchmarks", []string{"format=json"}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Benchmarks, nil
+}
+
+// GetVintages lists the vintages of census geographies available for the benchmark. An empty benchmark uses the one
+// configured on the client.
+func (c *Client) GetVintages(ctx context.Context, benchmark string) ([]*Vintage, error) {
+	if benchmark == "" {
+		benchmark = c.Benchmark
+	}
+
+	result := &VintagesResponse{}
+
+	err := c.get(ctx, "/vintages", []string{param("benchmark", benchmark), "format=json"}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Vintages, nil
+}
+
+func addressParams(address *Address) []string {
+	// the following must be provided in the proper order
+	return []string{
+		param("street", address.Street),
+		param("city", address.City),
+		param("state", address.State),
+		param("zip", address.Zip),
+	}
+}
+
+// SearchByAddress finds the locations matching an address that has been broken into its components.
 func (c *Client) SearchByAddress(ctx context.Context, address *Address) (*SearchByAddressResponse, error) {
-	target := fmt.Sprintf("%s/locations/address", c.BaseURL)
+	params := append([]string{param("benchmark", c.Benchmark), "format=json"}, addressParams(address)...)
+	result := &SearchByAddressResponse{}
+
+	err := c.get(ctx, "/locations/address", params, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+// SearchByOneLineAddress finds the locations matching an address written on a single line (e.g. "4600 Silver Hill Rd,
+// Washington, DC 20233").
+func (c *Client) SearchByOneLineAddress(ctx context.Context, address string) (*SearchByAddressResponse, error) {
+	params := []string{param("benchmark", c.Benchmark), "format=json", param("address", address)}
+	result := &SearchByAddressResponse{}
+
+	err := c.get(ctx, "/locations/onelineaddress", params, result)
 	if err != nil {
 		return nil, err
 	}
 
-	req.URL.RawQuery = strings.Join([]string{
-		"benchmark=" + defaultBenchmark,
+	return result, nil
+}
+
+// SearchGeographiesByAddress behaves like SearchByAddress, but also returns the census geographies (state, county,
+// tract, and block) containing each match.
+func (c *Client) SearchGeographiesByAddress(ctx context.Context, address *Address) (*SearchByAddressResponse, error) {
+	params := append([]string{
+		param("benchmark", c.Benchmark),
+		param("vintage", c.Vintage),
 		"format=json",
-		// the following must be provided in the proper order
-		"street=" + url.QueryEscape(address.Street),
-		"city=" + url.QueryEscape(address.City),
-		"state=" + url.QueryEscape(address.State),
-		"zip=" + url.QueryEscape(address.Zip),
-	}, "&")
+	}, addressParams(address)...)
+	result := &SearchByAddressResponse{}
 
-	resp, err := http.DefaultClient.Do(req)
+	err := c.get(ctx, "/geographies/address", params, result)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
+	return result, nil
+}
+
+// SearchGeographiesByOneLineAddress behaves like SearchByOneLineAddress, but also returns the census geographies
+// containing each match.
+func (c *Client) SearchGeographiesByOneLineAddress(ctx context.Context, address string) (*SearchByAddressResponse, error) {
+	params := []string{
+		param("benchmark", c.Benchmark),
+		param("vintage", c.Vintage),
+		"format=json",
+		param("address", address),
+	}
 	result := &SearchByAddressResponse{}
 
-	err = json.NewDecoder(resp.Body).Decode(result)
+	err := c.get(ctx, "/geographies/onelineaddress", params, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SearchGeographiesByCoordinates returns the census geographies containing the point, which is useful for finding the
+// county, tract, and FIPS codes of a location that has no address.
+func (c *Client) SearchGeographiesByCoordinates(ctx context.Context, lat, long float32) (*SearchGeographiesResponse, error) {
+	params := []string{
+		param("benchmark", c.Benchmark),
+		param("vintage", c.Vintage),
+		"format=json",
+		param("x", strconv.FormatFloat(float64(long), 'f', -1, 32)),
+		param("y", strconv.FormatFloat(float64(lat), 'f', -1, 32)),
+	}
+	result := &SearchGeographiesResponse{}
+
+	err := c.get(ctx, "/geographies/coordinates", params, result)
 	if err != nil {
 		return nil, err
 	}
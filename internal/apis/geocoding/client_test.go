@@ -0,0 +1,174 @@
+package geocoding_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/apis/transport"
+)
+
+func TestSearchByAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/locations/address", r.URL.Path)
+		require.Equal(t, "1600 Pennsylvania Ave NW", r.URL.Query().Get("street"))
+		require.Equal(t, "homestead (farmer@example.com)", r.Header.Get("User-Agent"))
+
+		_, _ = w.Write([]byte(`{"result":{"addressMatches":[{
+			"matchedAddress": "1600 PENNSYLVANIA AVE NW, WASHINGTON, DC, 20500",
+			"coordinates": {"x": -77.03535, "y": 38.898754}
+		}]}}`))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(transport.NewClient(transport.Config{
+		UserAgent: "homestead",
+		Contact:   "farmer@example.com",
+	}))
+	client.BaseURL = server.URL
+
+	resp, err := client.SearchByAddress(context.Background(), &geocoding.Address{
+		Street: "1600 Pennsylvania Ave NW",
+		City:   "Washington",
+		State:  "DC",
+		Zip:    "20500",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Result.AddressMatches, 1)
+	require.InDelta(t, 38.898754, resp.Result.AddressMatches[0].Coordinates.Y, 0.0001)
+}
+
+func TestSearchByAddressError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":["Street or Address is required"],"status":"400"}`))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	_, err := client.SearchByAddress(context.Background(), &geocoding.Address{})
+	require.Error(t, err)
+
+	apiErr := &geocoding.Error{}
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, []string{"Street or Address is required"}, apiErr.Errors)
+}
+
+func TestSearchByOneLineAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/locations/onelineaddress", r.URL.Path)
+		require.Equal(t, "benchmark=Public_AR_Census2020&format=json&address=4600+Silver+Hill+Rd%2C+Washington%2C+DC+20233",
+			r.URL.RawQuery)
+
+		_, _ = w.Write([]byte(`{"result":{"addressMatches":[{
+			"matchedAddress": "4600 SILVER HILL RD, WASHINGTON, DC, 20233",
+			"coordinates": {"x": -76.92744, "y": 38.845985}
+		}]}}`))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+	client.Configure(geocoding.Config{Benchmark: "Public_AR_Census2020"})
+
+	resp, err := client.SearchByOneLineAddress(context.Background(), "4600 Silver Hill Rd, Washington, DC 20233")
+	require.NoError(t, err)
+	require.Len(t, resp.Result.AddressMatches, 1)
+	require.Equal(t, "4600 SILVER HILL RD, WASHINGTON, DC, 20233", resp.Result.AddressMatches[0].MatchedAddress)
+}
+
+func TestSearchGeographiesByCoordinates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/geographies/coordinates", r.URL.Path)
+		require.Equal(t, "Public_AR_Current", r.URL.Query().Get("benchmark"))
+		require.Equal(t, "Current_Current", r.URL.Query().Get("vintage"))
+		require.Equal(t, "-95.6752", r.URL.Query().Get("x"))
+		require.Equal(t, "39.0473", r.URL.Query().Get("y"))
+
+		_, _ = w.Write([]byte(`{"result":{
+			"input": {"location": {"x": -95.6752, "y": 39.0473}, "vintage": {"vintageName": "Current_Current"}},
+			"geographies": {
+				"States": [{"GEOID": "20", "NAME": "Kansas", "STUSAB": "KS", "STATE": "20"}],
+				"Counties": [{"GEOID": "20177", "NAME": "Shawnee County", "STATE": "20", "COUNTY": "177"}],
+				"Census Tracts": [{"GEOID": "20177000800", "NAME": "Census Tract 8", "TRACT": "000800"}],
+				"2010 Census Blocks": [{"GEOID": "201770008001000"}],
+				"2020 Census Blocks": [{"GEOID": "201770008002004", "BLOCK": "2004"}]
+			}
+		}}`))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	resp, err := client.SearchGeographiesByCoordinates(context.Background(), 39.0473, -95.6752)
+	require.NoError(t, err)
+	require.Equal(t, "Current_Current", resp.Result.Input.Vintage.Name)
+	require.Equal(t, "Shawnee County", resp.Result.Geographies.County().Name)
+	require.Equal(t, geocoding.FIPS{
+		State:  "20",
+		County: "20177",
+		Tract:  "20177000800",
+		Block:  "201770008002004",
+	}, resp.Result.Geographies.FIPS())
+}
+
+func TestSearchGeographiesByAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/geographies/address", r.URL.Path)
+		require.Equal(t, "Current_Current", r.URL.Query().Get("vintage"))
+
+		_, _ = w.Write([]byte(`{"result":{"addressMatches":[{
+			"matchedAddress": "1 MAIN ST, TOPEKA, KS, 66603",
+			"coordinates": {"x": -95.6752, "y": 39.0473},
+			"geographies": {"Counties": [{"GEOID": "20177", "NAME": "Shawnee County"}]}
+		}]}}`))
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	resp, err := client.SearchGeographiesByAddress(context.Background(), &geocoding.Address{Street: "1 Main St"})
+	require.NoError(t, err)
+	require.Equal(t, "20177", resp.Result.AddressMatches[0].Geographies.FIPS().County)
+	require.Nil(t, resp.Result.AddressMatches[0].Geographies.Tract())
+}
+
+func TestBenchmarksAndVintages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/benchmarks":
+			_, _ = w.Write([]byte(`{"benchmarks":[
+				{"id": "4", "benchmarkName": "Public_AR_Current", "isDefault": false},
+				{"id": "2020", "benchmarkName": "Public_AR_Census2020", "isDefault": false}
+			]}`))
+		case "/vintages":
+			require.Equal(t, "4", r.URL.Query().Get("benchmark"))
+			_, _ = w.Write([]byte(`{"vintages":[{"id": "4", "vintageName": "Current_Current", "isDefault": true}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	benchmarks, err := client.GetBenchmarks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 2)
+	require.Equal(t, "Public_AR_Census2020", benchmarks[1].Name)
+
+	vintages, err := client.GetVintages(context.Background(), "4")
+	require.NoError(t, err)
+	require.Len(t, vintages, 1)
+	require.True(t, vintages[0].IsDefault)
+}
@@ -0,0 +1,16 @@
+package geocoding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error describes a failed request, as returned by the API.
+type Error struct {
+	Errors []string `json:"errors,omitempty"`
+	Status string   `json:"status,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("geocoding failed (status %s): %s", e.Status, strings.Join(e.Errors, "; "))
+}
@@ -1,5 +1,10 @@
 package geocoding
 
+import (
+	"sort"
+	"strings"
+)
+
 type Benchmark struct {
 	ID          string `json:"id,omitempty"`
 	Name        string `json:"benchmarkName,omitempty"`
@@ -7,6 +12,21 @@ type Benchmark struct {
 	IsDefault   bool   `json:"isDefault,omitempty"`
 }
 
+type Vintage struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"vintageName,omitempty"`
+	Description string `json:"vintageDescription,omitempty"`
+	IsDefault   bool   `json:"isDefault,omitempty"`
+}
+
+type BenchmarksResponse struct {
+	Benchmarks []*Benchmark `json:"benchmarks,omitempty"`
+}
+
+type VintagesResponse struct {
+	Vintages []*Vintage `json:"vintages,omitempty"`
+}
+
 type Address struct {
 	Street string `json:"street" usage:"street address of the location to build an index for"`
 	City   string `json:"city"   usage:"city of the street address"`
@@ -39,16 +59,110 @@ type AddressComponents struct {
 	Zip             string `json:"zip,omitempty"`
 }
 
+// Geography describes a census geography (such as a county or tract) that contains a location. Only the attributes
+// common to most geographies are decoded.
+type Geography struct {
+	GEOID             string `json:"GEOID,omitempty"` // the full FIPS code of the geography
+	Name              string `json:"NAME,omitempty"`
+	BaseName          string `json:"BASENAME,omitempty"`
+	State             string `json:"STATE,omitempty"`  // state FIPS code
+	County            string `json:"COUNTY,omitempty"` // county FIPS code, within the state
+	Tract             string `json:"TRACT,omitempty"`
+	Block             string `json:"BLOCK,omitempty"`
+	StateAbbreviation string `json:"STUSAB,omitempty"`
+	CentroidLatitude  string `json:"CENTLAT,omitempty"`
+	CentroidLongitude string `json:"CENTLON,omitempty"`
+	MTFCC             string `json:"MTFCC,omitempty"` // MAF/TIGER feature class code
+}
+
+// Geographies groups the geographies containing a location by the name of their layer (e.g. "Counties").
+type Geographies map[string][]*Geography
+
+func (g Geographies) first(layer string) *Geography {
+	if len(g[layer]) == 0 {
+		return nil
+	}
+
+	return g[layer][0]
+}
+
+func (g Geographies) State() *Geography {
+	return g.first("States")
+}
+
+func (g Geographies) County() *Geography {
+	return g.first("Counties")
+}
+
+func (g Geographies) Tract() *Geography {
+	return g.first("Census Tracts")
+}
+
+// Block returns the census block containing the location. The name of the layer depends on the vintage (e.g. "2020
+// Census Blocks").
+func (g Geographies) Block() *Geography {
+	layers := make([]string, 0, len(g))
+	for layer := range g {
+		if strings.HasSuffix(layer, "Census Blocks") {
+			layers = append(layers, layer)
+		}
+	}
+
+	if len(layers) == 0 {
+		return nil
+	}
+
+	// prefer the most recent census
+	sort.Strings(layers)
+
+	return g.first(layers[len(layers)-1])
+}
+
+// FIPS contains the Federal Information Processing Standards codes identifying the geographies containing a location.
+// Each code includes the codes of the geographies containing it (e.g. the county code includes the state code).
+type FIPS struct {
+	State  string `json:"state,omitempty"`  // 2 digits
+	County string `json:"county,omitempty"` // 5 digits
+	Tract  string `json:"tract,omitempty"`  // 11 digits
+	Block  string `json:"block,omitempty"`  // 15 digits
+}
+
+// FIPS returns the codes of the geographies that were returned.
+func (g Geographies) FIPS() FIPS {
+	fips := FIPS{}
+
+	if state := g.State(); state != nil {
+		fips.State = state.GEOID
+	}
+
+	if county := g.County(); county != nil {
+		fips.County = county.GEOID
+	}
+
+	if tract := g.Tract(); tract != nil {
+		fips.Tract = tract.GEOID
+	}
+
+	if block := g.Block(); block != nil {
+		fips.Block = block.GEOID
+	}
+
+	return fips
+}
+
 type AddressMatch struct {
 	MatchedAddress    string             `json:"matchedAddress,omitempty"`
 	Coordinates       *Coordinates       `json:"coordinates,omitempty"`
 	TigerLine         *TigerLine         `json:"tigerLine,omitempty"`
 	AddressComponents *AddressComponents `json:"addressComponents,omitempty"`
+	Geographies       Geographies        `json:"geographies,omitempty"` // only returned when searching geographies
 }
 
 type SearchInput struct {
-	Benchmark *Benchmark `json:"benchmark,omitempty"`
-	Address   *Address   `json:"address,omitempty"`
+	Benchmark *Benchmark   `json:"benchmark,omitempty"`
+	Vintage   *Vintage     `json:"vintage,omitempty"`
+	Address   *Address     `json:"address,omitempty"`
+	Location  *Coordinates `json:"location,omitempty"`
 }
 
 type SearchResult struct {
@@ -59,3 +173,12 @@ type SearchResult struct {
 type SearchByAddressResponse struct {
 	Result *SearchResult `json:"result,omitempty"`
 }
+
+type GeographiesResult struct {
+	Input       *SearchInput `json:"input,omitempty"`
+	Geographies Geographies  `json:"geographies,omitempty"`
+}
+
+type SearchGeographiesResponse struct {
+	Result *GeographiesResult `json:"result,omitempty"`
+}
@@ -0,0 +1,311 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+const (
+	// CacheHeader is added to every response that passes through the cache, describing how it was served.
+	CacheHeader = "X-Homestead-Cache"
+
+	// CacheMiss indicates the response was retrieved from the server.
+	CacheMiss = "miss"
+	// CacheHit indicates a fresh response was served from disk without contacting the server.
+	CacheHit = "hit"
+	// CacheRevalidated indicates the server confirmed the cached response was unchanged.
+	CacheRevalidated = "revalidated"
+)
+
+type CacheConfig struct {
+	Directory string        `json:"directory" usage:"directory used to cache API responses between runs (disabled when empty)"`
+	MaxAge    time.Duration `json:"max_age"   usage:"how long an entry is kept after it was last stored before it is removed (0 keeps entries forever)" default:"168h"`
+}
+
+// NewCache wraps the provided RoundTripper with an on-disk cache for GET requests. Responses are served from disk while
+// they are fresh according to their Cache-Control or Expires headers. Once stale, the request is sent with
+// If-None-Match and If-Modified-Since headers so unchanged resources are not downloaded again. Entries stored more
+// than MaxAge ago are removed before the first request is served, since URLs that are never requested again (such as
+// those with a changing start time) would otherwise accumulate forever.
+func NewCache(base http.RoundTripper, cfg CacheConfig) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &cache{base: base, directory: cfg.Directory, maxAge: cfg.MaxAge}
+}
+
+type entry struct {
+	StoredAt   time.Time   `json:"stored_at"`
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// expires returns when the entry becomes stale. Entries without explicit freshness information are always stale.
+func (e *entry) expires() time.Time {
+	directives := cacheControl(e.Header)
+
+	if _, ok := directives["no-cache"]; ok {
+		return time.Time{}
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return time.Time{}
+		}
+
+		age, _ := strconv.Atoi(e.Header.Get("Age"))
+
+		return e.StoredAt.Add(time.Duration(seconds-age) * time.Second)
+	}
+
+	if expires, err := http.ParseTime(e.Header.Get("Expires")); err == nil {
+		return expires
+	}
+
+	return time.Time{}
+}
+
+func (e *entry) response(req *http.Request, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheHeader, status)
+
+	return &http.Response{
+		Status:        e.Status,
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+type cache struct {
+	base      http.RoundTripper
+	directory string
+	maxAge    time.Duration
+	prune     sync.Once
+}
+
+func (c *cache) path(req *http.Request) string {
+	// responses vary on the representation requested
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept")))
+	return filepath.Join(c.directory, hex.EncodeToString(sum[:]))
+}
+
+func (c *cache) load(path string) *entry {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	e := &entry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil
+	}
+
+	return e
+}
+
+func (c *cache) store(path string, e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(c.directory, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(c.directory, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// removeExpired deletes the entries that were last stored before the cutoff. Temporary files left behind by an
+// interrupted store are skipped since they may belong to a concurrent writer.
+func (c *cache) removeExpired(cutoff time.Time) error {
+	files, err := ioutil.ReadDir(c.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(c.directory, file.Name())
+
+		// unreadable entries are never served, so they are removed along with the expired ones
+		e := c.load(path)
+		if e != nil && !e.StoredAt.Before(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return c.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	now := clocks.Extract(ctx).Now()
+	log := zaputil.Extract(ctx)
+	path := c.path(req)
+
+	if c.maxAge > 0 {
+		c.prune.Do(func() {
+			if err := c.removeExpired(now.Add(-c.maxAge)); err != nil {
+				log.Warn("failed to remove expired cache entries", zap.Error(err))
+			}
+		})
+	}
+
+	cached := c.load(path)
+	if cached != nil {
+		if now.Before(cached.expires()) {
+			return cached.response(req, CacheHit), nil
+		}
+
+		etag := cached.Header.Get("ETag")
+		lastModified := cached.Header.Get("Last-Modified")
+
+		if etag != "" || lastModified != "" {
+			req = req.Clone(ctx)
+
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+
+			if lastModified != "" {
+				req.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		_ = resp.Body.Close()
+
+		// refresh the freshness information while retaining the original representation
+		for key, values := range resp.Header {
+			cached.Header[key] = values
+		}
+
+		cached.StoredAt = now
+
+		if err := c.store(path, cached); err != nil {
+			log.Warn("failed to update cache entry", zap.String("url", req.URL.String()), zap.Error(err))
+		}
+
+		return cached.response(req, CacheRevalidated), nil
+	}
+
+	resp.Header.Set(CacheHeader, CacheMiss)
+
+	if _, noStore := cacheControl(resp.Header)["no-store"]; resp.StatusCode != http.StatusOK || noStore {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del(CacheHeader)
+
+	err = c.store(path, &entry{
+		StoredAt:   now,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     header,
+		Body:       body,
+	})
+
+	if err != nil {
+		log.Warn("failed to write cache entry", zap.String("url", req.URL.String()), zap.Error(err))
+	}
+
+	return resp, nil
+}
+
+// cacheControl parses the directives of the Cache-Control header into a map.
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			key, val := directive, ""
+			if i := strings.Index(directive, "="); i >= 0 {
+				key, val = directive[:i], strings.Trim(directive[i+1:], `"`)
+			}
+
+			directives[strings.ToLower(key)] = val
+		}
+	}
+
+	return directives
+}
@@ -0,0 +1,144 @@
+package transport_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
+	"github.com/mjpitz/myago/clocks"
+)
+
+func get(t *testing.T, client *http.Client, url string) (string, string) {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return string(body), resp.Header.Get(transport.CacheHeader)
+}
+
+func TestCacheFresh(t *testing.T) {
+	requests := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_, _ = w.Write([]byte("point"))
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Cache: transport.CacheConfig{Directory: t.TempDir()},
+	})
+
+	body, cached := get(t, client, server.URL)
+	require.Equal(t, "point", body)
+	require.Equal(t, transport.CacheMiss, cached)
+
+	body, cached = get(t, client, server.URL)
+	require.Equal(t, "point", body)
+	require.Equal(t, transport.CacheHit, cached)
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	version := int32(1)
+	conditional := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v1"`
+		if atomic.LoadInt32(&version) > 1 {
+			etag = `"v2"`
+		}
+
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", etag)
+
+		if r.Header.Get("If-None-Match") != "" {
+			atomic.AddInt32(&conditional, 1)
+
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		_, _ = w.Write([]byte("gridpoint " + etag))
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Cache: transport.CacheConfig{Directory: t.TempDir()},
+	})
+
+	body, cached := get(t, client, server.URL)
+	require.Equal(t, `gridpoint "v1"`, body)
+	require.Equal(t, transport.CacheMiss, cached)
+
+	body, cached = get(t, client, server.URL)
+	require.Equal(t, `gridpoint "v1"`, body)
+	require.Equal(t, transport.CacheRevalidated, cached)
+
+	atomic.StoreInt32(&version, 2)
+
+	body, cached = get(t, client, server.URL)
+	require.Equal(t, `gridpoint "v2"`, body)
+	require.Equal(t, transport.CacheMiss, cached)
+
+	require.Equal(t, int32(2), atomic.LoadInt32(&conditional))
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	ctx := clocks.ToContext(context.Background(), clock)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_, _ = w.Write([]byte("observations"))
+	}))
+	defer server.Close()
+
+	cfg := transport.Config{
+		Cache: transport.CacheConfig{Directory: t.TempDir(), MaxAge: 24 * time.Hour},
+	}
+
+	fetch := func(client *http.Client, url string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	entries := func() int {
+		files, err := ioutil.ReadDir(cfg.Cache.Directory)
+		require.NoError(t, err)
+		return len(files)
+	}
+
+	client := transport.NewClient(cfg)
+	fetch(client, server.URL+"?start=1")
+	fetch(client, server.URL+"?start=2")
+	require.Equal(t, 2, entries())
+
+	// a later run removes the entries stored more than a day ago before serving its first request
+	clock.Advance(25 * time.Hour)
+
+	fetch(transport.NewClient(cfg), server.URL+"?start=3")
+	require.Equal(t, 1, entries())
+}
@@ -0,0 +1,161 @@
+package transport
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+type RetryConfig struct {
+	MaxAttempts    int           `json:"max_attempts"    usage:"the maximum number of attempts made for a single request" default:"5"`
+	InitialBackoff time.Duration `json:"initial_backoff" usage:"the maximum delay before the first retry" default:"500ms"`
+	MaxBackoff     time.Duration `json:"max_backoff"     usage:"the maximum delay between any two attempts" default:"30s"`
+}
+
+// DefaultRetry is the retry policy used by clients that are not configured using flags, matching the flag defaults.
+var DefaultRetry = RetryConfig{MaxAttempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second}
+
+// Retryable reports whether a request that received the provided status code should be attempted again.
+func Retryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// RetryAfter parses the Retry-After header of the provided response, which may either be a number of seconds or an
+// HTTP date.
+func RetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return at.Sub(now), true
+	}
+
+	return 0, false
+}
+
+// NewRetry wraps the provided RoundTripper so that failed requests are retried using an exponential backoff with full
+// jitter. Connection errors and 429, 500, 502, 503, and 504 responses are retried, honoring any Retry-After header
+// returned by the server. When the server asks for a longer wait than MaxBackoff, the response is returned immediately
+// instead. Requests with a body are only retried when the body can be replayed.
+func NewRetry(base http.RoundTripper, cfg RetryConfig) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &retry{base: base, cfg: cfg}
+}
+
+type retry struct {
+	base http.RoundTripper
+	cfg  RetryConfig
+}
+
+func (r *retry) backoff(attempt int) time.Duration {
+	ceiling := float64(r.cfg.InitialBackoff) * math.Pow(2, float64(attempt))
+	if max := float64(r.cfg.MaxBackoff); max > 0 && ceiling > max {
+		ceiling = max
+	}
+
+	if ceiling < 1 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
+
+func (r *retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	clock := clocks.Extract(ctx)
+	log := zaputil.Extract(ctx)
+
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req.Body = body
+		}
+
+		resp, err := r.base.RoundTrip(req)
+
+		switch {
+		case attempt+1 >= r.cfg.MaxAttempts || !replayable:
+			return resp, err
+		case err != nil && ctx.Err() != nil:
+			// the caller gave up, as opposed to a single attempt timing out
+			return resp, err
+		case err == nil && !Retryable(resp.StatusCode):
+			return resp, err
+		}
+
+		delay := r.backoff(attempt)
+
+		if err == nil {
+			if after, ok := RetryAfter(resp, clock.Now()); ok {
+				if r.cfg.MaxBackoff > 0 && after > r.cfg.MaxBackoff {
+					// waiting that long would stall the run, so leave it to the caller to try again later
+					log.Warn("not retrying request",
+						zap.String("url", req.URL.String()),
+						zap.Int("status", resp.StatusCode),
+						zap.Duration("retry_after", after))
+
+					return resp, nil
+				}
+
+				delay = after
+				if delay < 0 {
+					delay = 0
+				}
+			}
+
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+
+			log.Warn("retrying request",
+				zap.String("url", req.URL.String()),
+				zap.Int("status", resp.StatusCode),
+				zap.Duration("delay", delay))
+		} else {
+			log.Warn("retrying request",
+				zap.String("url", req.URL.String()),
+				zap.Error(err),
+				zap.Duration("delay", delay))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-clock.After(delay):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package transport_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
+)
+
+func TestRetry(t *testing.T) {
+	attempts := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Retry: transport.RetryConfig{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+		},
+	})
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryExhausted(t *testing.T) {
+	attempts := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Retry: transport.RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		},
+	})
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	require.Error(t, transport.CheckResponse(resp))
+}
+
+func TestRetryAfterExceedsMaxBackoff(t *testing.T) {
+	attempts := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Retry: transport.RetryConfig{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+		},
+	})
+
+	// the server asks for a longer wait than allowed, so the response is returned without retrying
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 12, 15, 0, 0, 0, time.UTC)
+
+	resp := &http.Response{Header: http.Header{}}
+
+	_, ok := transport.RetryAfter(resp, now)
+	require.False(t, ok)
+
+	resp.Header.Set("Retry-After", "120")
+	delay, ok := transport.RetryAfter(resp, now)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, delay)
+
+	resp.Header.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+	delay, ok = transport.RetryAfter(resp, now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, delay)
+}
@@ -0,0 +1,185 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type Config struct {
+	UserAgent string        `json:"user_agent" usage:"the product name sent in the User-Agent header" default:"homestead"`
+	Contact   string        `json:"contact"    usage:"contact information (typically an email) appended to the User-Agent, as requested by weather.gov"`
+	Timeout   time.Duration `json:"timeout"    usage:"how long a single attempt may take before it is abandoned" default:"30s"`
+	RateLimit time.Duration `json:"rate_limit" usage:"the minimum time between requests, such as 500ms (0 disables limiting)"`
+	Burst     int           `json:"burst"      usage:"the number of requests that may exceed the rate limit at once" default:"1"`
+	Retry     RetryConfig   `json:"retry"`
+	Cache     CacheConfig   `json:"cache"`
+}
+
+// UserAgentString returns the User-Agent header value described by the configuration.
+func (c Config) UserAgentString() string {
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = "homestead"
+	}
+
+	if c.Contact != "" {
+		userAgent = fmt.Sprintf("%s (%s)", userAgent, c.Contact)
+	}
+
+	return userAgent
+}
+
+// NewClient constructs an http.Client that applies the provided configuration to every request using
+// http.DefaultTransport, which honors the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+func NewClient(cfg Config) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(http.DefaultTransport, cfg),
+	}
+}
+
+// NewTransport wraps the provided RoundTripper with the cache, retry policy, rate limit, User-Agent, and per-attempt
+// timeout described by the configuration.
+func NewTransport(base http.RoundTripper, cfg Config) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	base = &timeout{base: base, timeout: cfg.Timeout}
+	base = &userAgent{base: base, userAgent: cfg.UserAgentString()}
+
+	if cfg.RateLimit > 0 {
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+
+		base = &rateLimit{base: base, limiter: rate.NewLimiter(rate.Every(cfg.RateLimit), burst)}
+	}
+
+	base = NewRetry(base, cfg.Retry)
+
+	if cfg.Cache.Directory != "" {
+		base = NewCache(base, cfg.Cache)
+	}
+
+	return base
+}
+
+type userAgent struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (u *userAgent) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return u.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the provided request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", u.userAgent)
+
+	return u.base.RoundTrip(req)
+}
+
+type rateLimit struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (r *rateLimit) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := r.limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.base.RoundTrip(req)
+}
+
+type timeoutKey struct{}
+
+// WithTimeout overrides the per-attempt timeout of requests made using the returned context, such as for uploads that
+// take longer than the configured Timeout. A zero duration removes the per-attempt timeout entirely.
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+type timeout struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeout) RoundTrip(req *http.Request) (*http.Response, error) {
+	duration := t.timeout
+	if override, ok := req.Context().Value(timeoutKey{}).(time.Duration); ok {
+		duration = override
+	}
+
+	if duration <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), duration)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the deadline must continue to apply while the body is read
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// StatusError is returned when a server responds with an unexpected status code.
+type StatusError struct {
+	StatusCode  int
+	Status      string
+	ContentType string
+	Body        []byte
+}
+
+func (e *StatusError) Error() string {
+	body := strings.TrimSpace(string(e.Body))
+	if body == "" {
+		return fmt.Sprintf("unexpected response: %s", e.Status)
+	}
+
+	return fmt.Sprintf("unexpected response: %s: %s", e.Status, body)
+}
+
+// CheckResponse returns a StatusError containing the start of the response body when the response does not have a
+// 2xx status code.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	return &StatusError{
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	}
+}
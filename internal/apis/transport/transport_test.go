@@ -0,0 +1,126 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
+	"github.com/mjpitz/myago/flagset"
+)
+
+func TestUserAgent(t *testing.T) {
+	userAgent := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent <- r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		UserAgent: "homestead",
+		Contact:   "farmer@example.com",
+	})
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, "homestead (farmer@example.com)", <-userAgent)
+}
+
+func TestTimeout(t *testing.T) {
+	attempts := int32(0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Timeout: 50 * time.Millisecond,
+		Retry: transport.RetryConfig{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+		},
+	})
+
+	// the first attempt times out and the second succeeds
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestTimeoutOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		Timeout: 20 * time.Millisecond,
+		Retry:   transport.RetryConfig{MaxAttempts: 1},
+	})
+
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+
+	for _, timeout := range []time.Duration{0, time.Second} {
+		req, err := http.NewRequestWithContext(transport.WithTimeout(context.Background(), timeout), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := transport.NewClient(transport.Config{
+		RateLimit: 50 * time.Millisecond,
+		Burst:     1,
+	})
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	// the second and third requests each wait ~50ms for the limiter
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond))
+}
+
+func TestFlags(t *testing.T) {
+	names := make([]string, 0)
+	for _, flag := range flagset.Extract(&transport.Config{}) {
+		names = append(names, flag.Names()[0])
+	}
+
+	// every option must be settable from the command line
+	require.Subset(t, names, []string{"user_agent", "contact", "timeout", "rate_limit", "burst", "retry_max_attempts"})
+}
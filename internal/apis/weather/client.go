@@ -3,39 +3,79 @@ package weather
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
 )
 
 const defaultBaseURL = "https://api.weather.gov"
 
-func NewClient() *Client {
+// NewClient constructs a Client that issues requests using the provided http.Client. Passing a client created by
+// transport.NewClient ensures that transient failures are retried.
+func NewClient(client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
 	return &Client{
-		BaseURL: defaultBaseURL,
+		BaseURL:    defaultBaseURL,
+		HTTPClient: client,
 	}
 }
 
 type Client struct {
-	BaseURL string
+	BaseURL    string
+	HTTPClient *http.Client
 }
 
-func (c *Client) GetPoint(ctx context.Context, lat, long float32) (*PointProperties, error) {
-	target := fmt.Sprintf("%s/points/%.4f,%.4f", c.BaseURL, lat, long)
+// get requests the target and decodes the properties of the response into result.
+func (c *Client) get(ctx context.Context, target string, result interface{}) error {
+	return c.fetch(ctx, target, &Response{result})
+}
 
+// fetch requests the target and decodes the response into result. Responses without a 2xx status code return an
+// error, decoding application/problem+json bodies into a *Problem.
+func (c *Client) fetch(ctx context.Context, target string, result interface{}) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	req.Header.Set("Accept", "application/geo+json")
+
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
+	err = transport.CheckResponse(resp)
+	if err != nil {
+		statusErr := &transport.StatusError{}
+		if errors.As(err, &statusErr) && strings.Contains(statusErr.ContentType, "problem+json") {
+			problem := &Problem{}
+			if json.Unmarshal(statusErr.Body, problem) == nil {
+				return problem
+			}
+		}
+
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (c *Client) GetPoint(ctx context.Context, lat, long float32) (*PointProperties, error) {
+	target := fmt.Sprintf("%s/points/%.4f,%.4f", c.BaseURL, lat, long)
+
 	result := &PointProperties{}
 
-	err = json.NewDecoder(resp.Body).Decode(&Response{result})
+	err := c.get(ctx, target, result)
 	if err != nil {
 		return nil, err
 	}
@@ -46,20 +86,9 @@ func (c *Client) GetPoint(ctx context.Context, lat, long float32) (*PointPropert
 func (c *Client) GetGridpoint(ctx context.Context, gridID string, gridX, gridY int) (*GridpointProperties, error) {
 	target := fmt.Sprintf("%s/gridpoints/%s/%d,%d", c.BaseURL, gridID, gridX, gridY)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	result := &GridpointProperties{}
 
-	err = json.NewDecoder(resp.Body).Decode(&Response{result})
+	err := c.get(ctx, target, result)
 	if err != nil {
 		return nil, err
 	}
@@ -70,20 +99,22 @@ func (c *Client) GetGridpoint(ctx context.Context, gridID string, gridX, gridY i
 func (c *Client) GetForecast(ctx context.Context, gridID string, gridX, gridY int) (*ForecastProperties, error) {
 	target := fmt.Sprintf("%s/gridpoints/%s/%d,%d/forecast", c.BaseURL, gridID, gridX, gridY)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
-	if err != nil {
-		return nil, err
-	}
+	result := &ForecastProperties{}
 
-	resp, err := http.DefaultClient.Do(req)
+	err := c.get(ctx, target, result)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+
+	return result, nil
+}
+
+func (c *Client) GetHourlyForecast(ctx context.Context, gridID string, gridX, gridY int) (*ForecastProperties, error) {
+	target := fmt.Sprintf("%s/gridpoints/%s/%d,%d/forecast/hourly", c.BaseURL, gridID, gridX, gridY)
 
 	result := &ForecastProperties{}
 
-	err = json.NewDecoder(resp.Body).Decode(&Response{result})
+	err := c.get(ctx, target, result)
 	if err != nil {
 		return nil, err
 	}
@@ -91,26 +122,110 @@ func (c *Client) GetForecast(ctx context.Context, gridID string, gridX, gridY in
 	return result, nil
 }
 
-func (c *Client) GetHourlyForecast(ctx context.Context, gridID string, gridX, gridY int) (*ForecastProperties, error) {
-	target := fmt.Sprintf("%s/gridpoints/%s/%d,%d/forecast/hotargety", c.BaseURL, gridID, gridX, gridY)
+// GetActiveAlerts returns the alerts that are currently in effect for the provided area.
+func (c *Client) GetActiveAlerts(ctx context.Context, area AlertArea) ([]*Alert, error) {
+	query := url.Values{}
+
+	switch {
+	case area.Zone != "":
+		query.Set("zone", area.Zone)
+	case area.Point != nil:
+		query.Set("point", fmt.Sprintf("%.4f,%.4f", area.Point.Latitude, area.Point.Longitude))
+	default:
+		return nil, fmt.Errorf("either a point or zone is required to retrieve alerts")
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	target := fmt.Sprintf("%s/alerts/active?%s", c.BaseURL, query.Encode())
+
+	result := &AlertCollection{}
+
+	err := c.fetch(ctx, target, result)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	alerts := make([]*Alert, 0, len(result.Features))
+	for _, feature := range result.Features {
+		if feature.Properties != nil {
+			alerts = append(alerts, feature.Properties)
+		}
 	}
-	defer resp.Body.Close()
 
-	result := &ForecastProperties{}
+	return alerts, nil
+}
+
+// GetStations returns the observation stations near the provided point, ordered by their distance from it.
+func (c *Client) GetStations(ctx context.Context, lat, long float32) ([]*StationProperties, error) {
+	target := fmt.Sprintf("%s/points/%.4f,%.4f/stations", c.BaseURL, lat, long)
+
+	result := &StationCollection{}
 
-	err = json.NewDecoder(resp.Body).Decode(&Response{result})
+	err := c.fetch(ctx, target, result)
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	stations := make([]*StationProperties, 0, len(result.Features))
+	for _, feature := range result.Features {
+		if feature.Properties != nil {
+			stations = append(stations, feature.Properties)
+		}
+	}
+
+	return stations, nil
+}
+
+// maxObservationPages bounds the number of pages followed by GetObservations.
+const maxObservationPages = 100
+
+// GetObservations returns the observations made by a station between start and end (inclusive), following pagination
+// links until every observation has been retrieved. A zero start or end leaves that side of the range open. Rather than
+// return a partial result, an error is returned when the observations span more than maxObservationPages pages.
+func (c *Client) GetObservations(ctx context.Context, stationID string, start, end time.Time) ([]*ObservationProperties, error) {
+	query := url.Values{}
+
+	if !start.IsZero() {
+		query.Set("start", start.UTC().Format(time.RFC3339))
+	}
+
+	if !end.IsZero() {
+		query.Set("end", end.UTC().Format(time.RFC3339))
+	}
+
+	target := fmt.Sprintf("%s/stations/%s/observations", c.BaseURL, url.PathEscape(stationID))
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	observations := make([]*ObservationProperties, 0)
+
+	for page := 0; target != ""; page++ {
+		if page == maxObservationPages {
+			return nil, fmt.Errorf("observations for %s span more than %d pages, try a shorter range", stationID, maxObservationPages)
+		}
+
+		result := &ObservationCollection{}
+
+		err := c.fetch(ctx, target, result)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result.Features) == 0 {
+			break
+		}
+
+		for _, feature := range result.Features {
+			if feature.Properties != nil {
+				observations = append(observations, feature.Properties)
+			}
+		}
+
+		target = ""
+		if result.Pagination != nil {
+			target = result.Pagination.Next
+		}
+	}
+
+	return observations, nil
 }
@@ -0,0 +1,179 @@
+package weather_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/weather"
+)
+
+func TestGetGridpointProblem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/gridpoints/TOP/31,80", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{
+			"type": "https://api.weather.gov/problems/UnexpectedProblem",
+			"title": "Unexpected Problem",
+			"status": 500,
+			"detail": "An unexpected problem has occurred.",
+			"instance": "urn:noaa:nws:api:request:493c3a1d",
+			"correlationId": "493c3a1d"
+		}`))
+	}))
+	defer server.Close()
+
+	client := weather.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	_, err := client.GetGridpoint(context.Background(), "TOP", 31, 80)
+	require.Error(t, err)
+
+	problem := &weather.Problem{}
+	require.True(t, errors.As(err, &problem))
+	require.Equal(t, 500, problem.Status)
+	require.Equal(t, "Unexpected Problem", problem.Title)
+	require.Equal(t, "493c3a1d", problem.CorrelationID)
+}
+
+func TestGetActiveAlerts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/alerts/active", r.URL.Path)
+		require.Equal(t, "KSZ040", r.URL.Query().Get("zone"))
+
+		w.Header().Set("Content-Type", "application/geo+json")
+		_, _ = w.Write([]byte(`{
+			"type": "FeatureCollection",
+			"features": [{
+				"id": "https://api.weather.gov/alerts/urn:oid:2.49.0.1.840.0.2",
+				"properties": {
+					"id": "urn:oid:2.49.0.1.840.0.2",
+					"areaDesc": "Shawnee",
+					"affectedZones": ["https://api.weather.gov/zones/forecast/KSZ040"],
+					"references": [{
+						"@id": "https://api.weather.gov/alerts/urn:oid:2.49.0.1.840.0.1",
+						"identifier": "urn:oid:2.49.0.1.840.0.1",
+						"sender": "w-nws.webmaster@noaa.gov",
+						"sent": "2022-01-12T03:00:00-06:00"
+					}],
+					"sent": "2022-01-12T09:00:00-06:00",
+					"onset": "2022-01-13T01:00:00-06:00",
+					"ends": null,
+					"messageType": "Update",
+					"severity": "Moderate",
+					"urgency": "Expected",
+					"event": "Frost Advisory",
+					"parameters": {"NWSheadline": ["FROST ADVISORY IN EFFECT"]}
+				}
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := weather.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	alerts, err := client.GetActiveAlerts(context.Background(), weather.AlertArea{Zone: "KSZ040"})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	alert := alerts[0]
+	require.Equal(t, "urn:oid:2.49.0.1.840.0.2", alert.ID)
+	require.Equal(t, "Frost Advisory", alert.Event)
+	require.Equal(t, weather.MessageTypeUpdate, alert.MessageType)
+	require.True(t, alert.Ends.IsZero())
+	require.Len(t, alert.References, 1)
+	require.Equal(t, "urn:oid:2.49.0.1.840.0.1", alert.References[0].Identifier)
+	require.Equal(t, []string{"FROST ADVISORY IN EFFECT"}, alert.Parameters["NWSheadline"])
+
+	_, err = client.GetActiveAlerts(context.Background(), weather.AlertArea{})
+	require.Error(t, err)
+}
+
+func TestGetObservations(t *testing.T) {
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/stations/KTOP/observations", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/geo+json")
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			require.Equal(t, "2022-01-12T00:00:00Z", r.URL.Query().Get("start"))
+			require.Equal(t, "2022-01-13T00:00:00Z", r.URL.Query().Get("end"))
+
+			_, _ = w.Write([]byte(`{
+				"features": [{"properties": {
+					"station": "https://api.weather.gov/stations/KTOP",
+					"timestamp": "2022-01-12T15:00:00+00:00",
+					"temperature": {"unitCode": "wmoUnit:degC", "value": -2.5, "qualityControl": "V"},
+					"windGust": {"unitCode": "wmoUnit:km_h-1", "value": null, "qualityControl": "Z"}
+				}}],
+				"pagination": {"next": "` + server.URL + `/stations/KTOP/observations?cursor=2"}
+			}`))
+		case "2":
+			_, _ = w.Write([]byte(`{
+				"features": [{"properties": {
+					"station": "https://api.weather.gov/stations/KTOP",
+					"timestamp": "2022-01-12T14:00:00+00:00",
+					"temperature": {"unitCode": "wmoUnit:degC", "value": -3, "qualityControl": "X"}
+				}}],
+				"pagination": {"next": "` + server.URL + `/stations/KTOP/observations?cursor=3"}
+			}`))
+		default:
+			_, _ = w.Write([]byte(`{"features": []}`))
+		}
+	}))
+	defer server.Close()
+
+	client := weather.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	start := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	observations, err := client.GetObservations(context.Background(), "KTOP", start, start.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, observations, 2)
+
+	require.Equal(t, -2.5, *observations[0].Temperature.Value)
+	require.Equal(t, weather.QualityControlVerified, observations[0].Temperature.QualityControl)
+	require.Nil(t, observations[0].WindGust.Value)
+	require.Equal(t, weather.QualityControlRejected, observations[1].Temperature.QualityControl)
+}
+
+func TestGetObservationsPageLimit(t *testing.T) {
+	var server *httptest.Server
+
+	requests := int32(0)
+
+	// every page links to another, so pagination would never end on its own
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Content-Type", "application/geo+json")
+
+		_, _ = w.Write([]byte(`{
+			"features": [{"properties": {"timestamp": "2022-01-12T15:00:00+00:00"}}],
+			"pagination": {"next": "` + server.URL + `/stations/KTOP/observations?cursor=next"}
+		}`))
+	}))
+	defer server.Close()
+
+	client := weather.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	observations, err := client.GetObservations(context.Background(), "KTOP", time.Time{}, time.Time{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "more than 100 pages")
+	require.Nil(t, observations)
+	require.Equal(t, int32(100), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,19 @@
+package weather
+
+import (
+	"fmt"
+)
+
+// Problem describes a failed request, as returned by the API using application/problem+json (RFC 7807).
+type Problem struct {
+	Type          string `json:"type,omitempty"`
+	Title         string `json:"title,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	return fmt.Sprintf("%s (status %d, correlation id %s): %s", p.Title, p.Status, p.CorrelationID, p.Detail)
+}
@@ -1,6 +1,8 @@
 package weather
 
 import (
+	"time"
+
 	"github.com/mjpitz/homestead/internal/iso8601"
 )
 
@@ -113,6 +115,135 @@ type ForecastProperties struct {
 	Periods    []*Forecast `json:"periods,omitempty"`
 }
 
+const (
+	MessageTypeAlert  = "Alert"
+	MessageTypeUpdate = "Update"
+	MessageTypeCancel = "Cancel"
+)
+
+// AlertArea selects the area to retrieve alerts for. Either a Point or a Zone (e.g. KSZ040) must be provided.
+type AlertArea struct {
+	Point *Coordinates
+	Zone  string
+}
+
+type AlertReference struct {
+	URL        string    `json:"@id,omitempty"`
+	Identifier string    `json:"identifier,omitempty"`
+	Sender     string    `json:"sender,omitempty"`
+	Sent       time.Time `json:"sent,omitempty"`
+}
+
+type Alert struct {
+	ID            string              `json:"id,omitempty"`
+	AreaDesc      string              `json:"areaDesc,omitempty"`
+	AffectedZones []string            `json:"affectedZones,omitempty"`
+	References    []*AlertReference   `json:"references,omitempty"`
+	Sent          time.Time           `json:"sent,omitempty"`
+	Effective     time.Time           `json:"effective,omitempty"`
+	Onset         time.Time           `json:"onset,omitempty"`
+	Expires       time.Time           `json:"expires,omitempty"`
+	Ends          time.Time           `json:"ends,omitempty"`
+	Status        string              `json:"status,omitempty"`
+	MessageType   string              `json:"messageType,omitempty"`
+	Category      string              `json:"category,omitempty"`
+	Severity      string              `json:"severity,omitempty"`
+	Certainty     string              `json:"certainty,omitempty"`
+	Urgency       string              `json:"urgency,omitempty"`
+	Event         string              `json:"event,omitempty"`
+	Sender        string              `json:"sender,omitempty"`
+	SenderName    string              `json:"senderName,omitempty"`
+	Headline      string              `json:"headline,omitempty"`
+	Description   string              `json:"description,omitempty"`
+	Instruction   string              `json:"instruction,omitempty"`
+	Response      string              `json:"response,omitempty"`
+	Parameters    map[string][]string `json:"parameters,omitempty"`
+}
+
+type AlertFeature struct {
+	Properties *Alert `json:"properties,omitempty"`
+}
+
+type AlertCollection struct {
+	Title    string          `json:"title,omitempty"`
+	Updated  string          `json:"updated,omitempty"`
+	Features []*AlertFeature `json:"features,omitempty"`
+}
+
+// Quality control flags applied to observed values by the MADIS quality control checks.
+const (
+	QualityControlPreliminary = "Z" // no QC applied yet
+	QualityControlCoarsePass  = "C" // passed level 1
+	QualityControlScreened    = "S" // passed levels 1 and 2
+	QualityControlVerified    = "V" // passed levels 1, 2, and 3
+	QualityControlRejected    = "X" // failed level 1
+	QualityControlQuestioned  = "Q" // passed level 1, failed 2 or 3
+	QualityControlSubjective  = "G" // subjectively good
+	QualityControlBad         = "B" // subjectively bad
+	QualityControlTemporal    = "T" // virtual temporal consistency check
+)
+
+// QuantitativeValue is a single observed value. The Value is nil when a station did not report the measurement.
+type QuantitativeValue struct {
+	UnitCode       string   `json:"unitCode,omitempty"`
+	Value          *float64 `json:"value,omitempty"`
+	QualityControl string   `json:"qualityControl,omitempty"`
+}
+
+type StationProperties struct {
+	URL               string     `json:"@id,omitempty"`
+	StationIdentifier string     `json:"stationIdentifier,omitempty"`
+	Name              string     `json:"name,omitempty"`
+	TimeZone          string     `json:"timeZone,omitempty"`
+	Elevation         *Elevation `json:"elevation,omitempty"`
+	Forecast          string     `json:"forecast,omitempty"`
+	County            string     `json:"county,omitempty"`
+}
+
+type StationFeature struct {
+	Properties *StationProperties `json:"properties,omitempty"`
+}
+
+type StationCollection struct {
+	Features []*StationFeature `json:"features,omitempty"`
+}
+
+type ObservationProperties struct {
+	Station                   string             `json:"station,omitempty"`
+	Timestamp                 time.Time          `json:"timestamp,omitempty"`
+	TextDescription           string             `json:"textDescription,omitempty"`
+	Elevation                 *QuantitativeValue `json:"elevation,omitempty"`
+	Temperature               *QuantitativeValue `json:"temperature,omitempty"`
+	Dewpoint                  *QuantitativeValue `json:"dewpoint,omitempty"`
+	WindDirection             *QuantitativeValue `json:"windDirection,omitempty"`
+	WindSpeed                 *QuantitativeValue `json:"windSpeed,omitempty"`
+	WindGust                  *QuantitativeValue `json:"windGust,omitempty"`
+	BarometricPressure        *QuantitativeValue `json:"barometricPressure,omitempty"`
+	SeaLevelPressure          *QuantitativeValue `json:"seaLevelPressure,omitempty"`
+	Visibility                *QuantitativeValue `json:"visibility,omitempty"`
+	MaxTemperatureLast24Hours *QuantitativeValue `json:"maxTemperatureLast24Hours,omitempty"`
+	MinTemperatureLast24Hours *QuantitativeValue `json:"minTemperatureLast24Hours,omitempty"`
+	PrecipitationLastHour     *QuantitativeValue `json:"precipitationLastHour,omitempty"`
+	PrecipitationLast3Hours   *QuantitativeValue `json:"precipitationLast3Hours,omitempty"`
+	PrecipitationLast6Hours   *QuantitativeValue `json:"precipitationLast6Hours,omitempty"`
+	RelativeHumidity          *QuantitativeValue `json:"relativeHumidity,omitempty"`
+	WindChill                 *QuantitativeValue `json:"windChill,omitempty"`
+	HeatIndex                 *QuantitativeValue `json:"heatIndex,omitempty"`
+}
+
+type ObservationFeature struct {
+	Properties *ObservationProperties `json:"properties,omitempty"`
+}
+
+type Pagination struct {
+	Next string `json:"next,omitempty"`
+}
+
+type ObservationCollection struct {
+	Features   []*ObservationFeature `json:"features,omitempty"`
+	Pagination *Pagination           `json:"pagination,omitempty"`
+}
+
 type Response struct {
 	Properties interface{} `json:"properties,omitempty"`
 }
@@ -0,0 +1,41 @@
+package datasets
+
+import (
+	"time"
+)
+
+// ForecastAccuracy scores the forecasts for a single field against the conditions observed by a station over a day.
+// Forecasts are grouped by their lead time, the difference between when a forecast was issued and the time it
+// describes.
+type ForecastAccuracy struct {
+	Date       time.Time `json:"date" gorm:"index"`
+	Station    string    `json:"station"`
+	Field      string    `json:"field"`
+	LeadTime   int       `json:"lead_time_h"` // the start of the lead time bucket, in hours
+	ComputedAt time.Time `json:"computed_at"`
+
+	Count int      `json:"count"` // the number of forecast and observation pairs that were scored
+	MAE   float64  `json:"mae"`   // mean absolute error
+	Bias  float64  `json:"bias"`  // mean of forecast minus observed
+	Brier *float64 `json:"brier"` // only computed for probabilities
+}
+
+func (f ForecastAccuracy) TableName() string {
+	return "forecast_accuracy"
+}
+
+func (f ForecastAccuracy) NaturalKey() []string {
+	return []string{"date", "station", "field", "lead_time_h"}
+}
+
+func (f ForecastAccuracy) RevisionKey() string {
+	return "computed_at"
+}
+
+func (f ForecastAccuracy) TimeKey() string {
+	return "date"
+}
+
+func (f ForecastAccuracy) LocationKey() string {
+	return "station"
+}
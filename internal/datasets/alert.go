@@ -0,0 +1,56 @@
+package datasets
+
+import (
+	"time"
+)
+
+// Alert is a watch, warning, or advisory issued by the National Weather Service. Alerts are keyed by their ID so
+// repeated runs update an alert in place. When a later alert updates or cancels an earlier one, the earlier alert is
+// marked as superseded.
+type Alert struct {
+	ID          string    `json:"id"`
+	Sent        time.Time `json:"sent" gorm:"index"`
+	Effective   time.Time `json:"effective"`
+	Onset       time.Time `json:"onset"`
+	Expires     time.Time `json:"expires"`
+	Ends        time.Time `json:"ends"`
+	ObservedAt  time.Time `json:"observed_at"` // the last time the alert was seen
+	Status      string    `json:"status"`
+	MessageType string    `json:"message_type"`
+	Category    string    `json:"category"`
+	Event       string    `json:"event"`
+	Severity    string    `json:"severity"`
+	Certainty   string    `json:"certainty"`
+	Urgency     string    `json:"urgency"`
+	Sender      string    `json:"sender"`
+	SenderName  string    `json:"sender_name"`
+	Headline    string    `json:"headline"`
+	Description string    `json:"description"`
+	Instruction string    `json:"instruction"`
+	Response    string    `json:"response"`
+	AreaDesc    string    `json:"area_desc"`
+	Zones       string    `json:"zones"`      // comma separated list of affected zones
+	Parameters  string    `json:"parameters"` // JSON encoded CAP parameters
+	References  string    `json:"references"` // comma separated list of alerts this alert updates or cancels
+
+	SupersededBy string `json:"superseded_by"` // the alert that updated or cancelled this alert
+	Cancelled    bool   `json:"cancelled"`
+}
+
+func (a Alert) TableName() string {
+	return "weather_alerts"
+}
+
+func (a Alert) NaturalKey() []string {
+	return []string{"id"}
+}
+
+// RevisionKey returns the time the alert was sent. Since an alert is never re-sent under the same ID, both index modes
+// store a single row per alert.
+func (a Alert) RevisionKey() string {
+	return "sent"
+}
+
+func (a Alert) TimeKey() string {
+	return "sent"
+}
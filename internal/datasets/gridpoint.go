@@ -0,0 +1,130 @@
+package datasets
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/resample"
+	"github.com/mjpitz/homestead/internal/units"
+)
+
+var dataPointsType = reflect.TypeOf(&weather.DataPoints{})
+
+// GridpointMapping links a *weather.DataPoints field of weather.GridpointProperties to the Weather column it populates.
+// Mappings are declared using the gridpoint and unit struct tags on Weather, where gridpoint contains the json name of
+// the source field and unit contains the WMO code of the columns unit. An optional resample tag forces the strategy
+// used to expand the values of the column (see resample.Strategy).
+type GridpointMapping struct {
+	// Source is the json name of the weather.GridpointProperties field.
+	Source string
+	// Column is the json name of the Weather column.
+	Column string
+	// Unit is the unit values are converted into before being written to the column.
+	Unit units.Unit
+	// Strategy is the resampling strategy required by the column. When empty, the configured strategy is used.
+	Strategy resample.Strategy
+
+	source []int
+	column []int
+}
+
+// DataPoints returns the source data points from the provided gridpoint, which may be nil.
+func (m *GridpointMapping) DataPoints(gridpoint *weather.GridpointProperties) *weather.DataPoints {
+	return reflect.ValueOf(gridpoint).Elem().FieldByIndex(m.source).Interface().(*weather.DataPoints)
+}
+
+// Set writes the value to the mapped column.
+func (m *GridpointMapping) Set(w *Weather, value float64) {
+	reflect.ValueOf(w).Elem().FieldByIndex(m.column).SetFloat(value)
+}
+
+var gridpointMappings struct {
+	once     sync.Once
+	mappings []*GridpointMapping
+	err      error
+}
+
+// GridpointMappings returns the mappings declared on Weather, ordered by column. An error is returned when a tag names
+// an unknown source field or unit, or when two columns are populated from the same source.
+func GridpointMappings() ([]*GridpointMapping, error) {
+	gridpointMappings.once.Do(func() {
+		gridpointMappings.mappings, gridpointMappings.err = parseGridpointMappings()
+	})
+
+	return gridpointMappings.mappings, gridpointMappings.err
+}
+
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+func parseGridpointMappings() ([]*GridpointMapping, error) {
+	sources := make(map[string]reflect.StructField)
+
+	gridpointType := reflect.TypeOf(weather.GridpointProperties{})
+	for i := 0; i < gridpointType.NumField(); i++ {
+		field := gridpointType.Field(i)
+		if field.Type == dataPointsType {
+			sources[jsonName(field)] = field
+		}
+	}
+
+	mapped := make(map[string]string)
+	mappings := make([]*GridpointMapping, 0, len(sources))
+
+	weatherType := reflect.TypeOf(Weather{})
+	for i := 0; i < weatherType.NumField(); i++ {
+		field := weatherType.Field(i)
+		column := jsonName(field)
+
+		name, ok := field.Tag.Lookup("gridpoint")
+		if !ok {
+			continue
+		}
+
+		source, ok := sources[name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%s: unknown gridpoint field %q", column, name)
+		case mapped[name] != "":
+			return nil, fmt.Errorf("%s: gridpoint field %q is already mapped to %s", column, name, mapped[name])
+		case field.Type.Kind() != reflect.Float64:
+			return nil, fmt.Errorf("%s: gridpoint data must be written to a float64 column", column)
+		}
+
+		unit := units.None
+		if code := field.Tag.Get("unit"); code != "" {
+			var err error
+
+			unit, err = units.Parse(code)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", column, err)
+			}
+		}
+
+		strategy := resample.Strategy("")
+		if tag := field.Tag.Get("resample"); tag != "" {
+			var err error
+
+			strategy, err = resample.ParseStrategy(tag)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", column, err)
+			}
+		}
+
+		mapped[name] = column
+		mappings = append(mappings, &GridpointMapping{
+			Source:   name,
+			Column:   column,
+			Unit:     unit,
+			Strategy: strategy,
+			source:   source.Index,
+			column:   field.Index,
+		})
+	}
+
+	return mappings, nil
+}
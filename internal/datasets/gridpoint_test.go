@@ -0,0 +1,53 @@
+package datasets_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/weather"
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/resample"
+	"github.com/mjpitz/homestead/internal/units"
+)
+
+func TestGridpointMappings(t *testing.T) {
+	mappings, err := datasets.GridpointMappings()
+	require.NoError(t, err)
+
+	bySource := make(map[string]*datasets.GridpointMapping, len(mappings))
+	for _, mapping := range mappings {
+		bySource[mapping.Source] = mapping
+	}
+
+	// every *DataPoints field must have a destination column
+	gridpointType := reflect.TypeOf(weather.GridpointProperties{})
+	for i := 0; i < gridpointType.NumField(); i++ {
+		field := gridpointType.Field(i)
+		if field.Type != reflect.TypeOf(&weather.DataPoints{}) {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		require.Contains(t, bySource, name, "GridpointProperties.%s has no destination column on Weather", field.Name)
+	}
+
+	temperature := bySource["temperature"]
+	require.Equal(t, "temperature_degc", temperature.Column)
+	require.Equal(t, units.Celsius, temperature.Unit)
+	require.Equal(t, resample.Strategy(""), temperature.Strategy)
+	require.Equal(t, resample.Accumulate, bySource["quantitativePrecipitation"].Strategy)
+
+	gridpoint := &weather.GridpointProperties{
+		Temperature: &weather.DataPoints{UnitOfMeasure: "wmoUnit:degC"},
+	}
+
+	require.Equal(t, gridpoint.Temperature, temperature.DataPoints(gridpoint))
+	require.Nil(t, bySource["dewpoint"].DataPoints(gridpoint))
+
+	doc := &datasets.Weather{}
+	temperature.Set(doc, 12.5)
+	require.Equal(t, 12.5, doc.Temperature)
+}
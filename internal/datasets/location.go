@@ -0,0 +1,42 @@
+package datasets
+
+import (
+	"time"
+)
+
+// DefaultLocation is the name assigned to documents that were written before they were associated with a location.
+const DefaultLocation = "home"
+
+// Location describes a site that documents are collected for. Other documents refer to a location by its name, while
+// the remaining columns are resolved from the geocoder and weather.gov each time the location is indexed. Locations
+// have no revisions, so the name identifies a single row regardless of the index mode.
+type Location struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Address   string  `json:"address"` // as matched by the geocoder, empty when coordinates were provided
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	GridID       string `json:"grid_id"`
+	GridX        int    `json:"grid_x"`
+	GridY        int    `json:"grid_y"`
+	TimeZone     string `json:"time_zone"`
+	RadarStation string `json:"radar_station"`
+}
+
+func (l Location) TableName() string {
+	return "locations"
+}
+
+func (l Location) NaturalKey() []string {
+	return []string{"name"}
+}
+
+func (l Location) RevisionKey() string {
+	return ""
+}
+
+func (l Location) LocationKey() string {
+	return "name"
+}
@@ -0,0 +1,68 @@
+package datasets
+
+import (
+	"time"
+)
+
+// Observation captures the conditions reported by a weather station. Measurements are nil when the station did not
+// report them, and each is paired with the quality control flag assigned by weather.gov (see weather.QualityControl*).
+type Observation struct {
+	Station    string    `json:"station"`
+	Timestamp  time.Time `json:"timestamp" gorm:"index"`
+	ObservedAt time.Time `json:"observed_at"`
+
+	Description string `json:"description"`
+
+	Temperature               *float64 `json:"temperature_degc"`
+	TemperatureQC             string   `json:"temperature_qc"`
+	Dewpoint                  *float64 `json:"dewpoint_degc"`
+	DewpointQC                string   `json:"dewpoint_qc"`
+	RelativeHumidity          *float64 `json:"relative_humidity_pct"`
+	RelativeHumidityQC        string   `json:"relative_humidity_qc"`
+	WindDirection             *float64 `json:"wind_direction"`
+	WindDirectionQC           string   `json:"wind_direction_qc"`
+	WindSpeed                 *float64 `json:"wind_speed_kph"` // kilometers per hour
+	WindSpeedQC               string   `json:"wind_speed_qc"`
+	WindGust                  *float64 `json:"wind_gust_kph"` // kilometers per hour
+	WindGustQC                string   `json:"wind_gust_qc"`
+	BarometricPressure        *float64 `json:"barometric_pressure_pa"`
+	BarometricPressureQC      string   `json:"barometric_pressure_qc"`
+	SeaLevelPressure          *float64 `json:"sea_level_pressure_pa"`
+	SeaLevelPressureQC        string   `json:"sea_level_pressure_qc"`
+	Visibility                *float64 `json:"visibility_m"`
+	VisibilityQC              string   `json:"visibility_qc"`
+	MaxTemperatureLast24Hours *float64 `json:"max_temperature_last_24_hours_degc"`
+	MaxTemperatureLast24QC    string   `json:"max_temperature_last_24_hours_qc"`
+	MinTemperatureLast24Hours *float64 `json:"min_temperature_last_24_hours_degc"`
+	MinTemperatureLast24QC    string   `json:"min_temperature_last_24_hours_qc"`
+	PrecipitationLastHour     *float64 `json:"precipitation_last_hour_mm"`
+	PrecipitationLastHourQC   string   `json:"precipitation_last_hour_qc"`
+	PrecipitationLast3Hours   *float64 `json:"precipitation_last_3_hours_mm"`
+	PrecipitationLast3HoursQC string   `json:"precipitation_last_3_hours_qc"`
+	PrecipitationLast6Hours   *float64 `json:"precipitation_last_6_hours_mm"`
+	PrecipitationLast6HoursQC string   `json:"precipitation_last_6_hours_qc"`
+	WindChill                 *float64 `json:"wind_chill_degc"`
+	WindChillQC               string   `json:"wind_chill_qc"`
+	HeatIndex                 *float64 `json:"heat_index_degc"`
+	HeatIndexQC               string   `json:"heat_index_qc"`
+}
+
+func (o Observation) TableName() string {
+	return "weather_observations"
+}
+
+func (o Observation) NaturalKey() []string {
+	return []string{"station", "timestamp"}
+}
+
+func (o Observation) RevisionKey() string {
+	return "observed_at"
+}
+
+func (o Observation) TimeKey() string {
+	return "timestamp"
+}
+
+func (o Observation) LocationKey() string {
+	return "station"
+}
@@ -0,0 +1,133 @@
+package datasets
+
+import (
+	"time"
+
+	"github.com/mjpitz/homestead/internal/units"
+)
+
+type Weather struct {
+	Timestamp  time.Time `json:"timestamp" gorm:"index"`
+	ObservedAt time.Time `json:"observed_at"`
+	IssuedAt   time.Time `json:"issued_at"` // when weather.gov last updated the forecast
+
+	// Location references the Location the forecast was retrieved for by its name, the key of the locations table.
+	Location string `json:"location"`
+
+	Elevation                        float64 `json:"elevation_m"`
+	Temperature                      float64 `json:"temperature_degc"                     gridpoint:"temperature"                      unit:"degC"`
+	Dewpoint                         float64 `json:"dewpoint_degc"                        gridpoint:"dewpoint"                         unit:"degC"`
+	MaxTemperature                   float64 `json:"max_temperature_degc"                 gridpoint:"maxTemperature"                   unit:"degC"`
+	MinTemperature                   float64 `json:"min_temperature_degc"                 gridpoint:"minTemperature"                   unit:"degC"`
+	RelativeHumidity                 float64 `json:"relative_humidity_pct"                gridpoint:"relativeHumidity"                 unit:"percent"`
+	ApparentTemperature              float64 `json:"apparent_temperature_degc"            gridpoint:"apparentTemperature"              unit:"degC"`
+	HeatIndex                        float64 `json:"heat_index_degc"                      gridpoint:"heatIndex"                        unit:"degC"`
+	WindChill                        float64 `json:"wind_chill_degc"                      gridpoint:"windChill"                        unit:"degC"`
+	SkyCover                         float64 `json:"sky_cover_pct"                        gridpoint:"skyCover"                         unit:"percent"`
+	WindDirection                    float64 `json:"wind_direction"                       gridpoint:"windDirection"                    unit:"degree_(angle)" resample:"step"`
+	WindSpeed                        float64 `json:"wind_speed_kph"                       gridpoint:"windSpeed"                        unit:"km_h-1"` // kilometers per hour
+	WindGust                         float64 `json:"wind_gust_kph"                        gridpoint:"windGust"                         unit:"km_h-1"` // kilometers per hour
+	ProbabilityOfPrecipitation       float64 `json:"precipitation_probability_pct"        gridpoint:"probabilityOfPrecipitation"       unit:"percent"`
+	QuantitativePrecipitation        float64 `json:"precipitation_quantity_mm"            gridpoint:"quantitativePrecipitation"        unit:"mm"             resample:"accumulate"`
+	IceAccumulation                  float64 `json:"ice_accumulation_mm"                  gridpoint:"iceAccumulation"                  unit:"mm"             resample:"accumulate"`
+	SnowfallAmount                   float64 `json:"snowfall_amount_mm"                   gridpoint:"snowfallAmount"                   unit:"mm"             resample:"accumulate"`
+	SnowLevel                        float64 `json:"snow_level"                           gridpoint:"snowLevel"                        unit:"m"`
+	CeilingHeight                    float64 `json:"ceiling_height"                       gridpoint:"ceilingHeight"                    unit:"m"`
+	Visibility                       float64 `json:"visibility"                           gridpoint:"visibility"                       unit:"m"`
+	TransportWindSpeed               float64 `json:"transport_wind_speed_kph"             gridpoint:"transportWindSpeed"               unit:"km_h-1"`
+	TransportWindDirection           float64 `json:"transport_wind_direction"             gridpoint:"transportWindDirection"           unit:"degree_(angle)" resample:"step"`
+	MixingHeight                     float64 `json:"mixing_height_m"                      gridpoint:"mixingHeight"                     unit:"m"`
+	HainesIndex                      float64 `json:"haines_index"                         gridpoint:"hainesIndex"`
+	LightningActivityLevel           float64 `json:"lightning_activity_level"             gridpoint:"lightningActivityLevel"`
+	TwentyFootWindSpeed              float64 `json:"twenty_foot_wind_speed_kph"           gridpoint:"twentyFootWindSpeed"              unit:"km_h-1"`
+	TwentyFootWindDirection          float64 `json:"twenty_foot_wind_direction"           gridpoint:"twentyFootWindDirection"          unit:"degree_(angle)" resample:"step"`
+	WaveHeight                       float64 `json:"wave_height"                          gridpoint:"waveHeight"                       unit:"m"`
+	WavePeriod                       float64 `json:"wave_period"                          gridpoint:"wavePeriod"                       unit:"s"`
+	PrimarySwellHeight               float64 `json:"primary_swell_height"                 gridpoint:"primarySwellHeight"               unit:"m"`
+	PrimarySwellDirection            float64 `json:"primary_swell_direction"              gridpoint:"primarySwellDirection"            unit:"degree_(angle)" resample:"step"`
+	SecondarySwellHeight             float64 `json:"secondary_swell_height"               gridpoint:"secondarySwellHeight"             unit:"m"`
+	SecondarySwellDirection          float64 `json:"secondary_swell_direction"            gridpoint:"secondarySwellDirection"          unit:"degree_(angle)" resample:"step"`
+	WavePeriod2                      float64 `json:"wave_period_2"                        gridpoint:"wavePeriod2"                      unit:"s"`
+	WindWaveHeight                   float64 `json:"wind_wave_height"                     gridpoint:"windWaveHeight"                   unit:"m"`
+	DispersionIndex                  float64 `json:"dispersion_index"                     gridpoint:"dispersionIndex"`
+	Pressure                         float64 `json:"pressure"                             gridpoint:"pressure"                         unit:"Pa"`
+	ProbabilityOfTropicalStormWinds  float64 `json:"probability_of_tropical_storm_winds"  gridpoint:"probabilityOfTropicalStormWinds"  unit:"percent"`
+	ProbabilityOfHurricaneWinds      float64 `json:"probability_of_hurricane_winds"       gridpoint:"probabilityOfHurricaneWinds"      unit:"percent"`
+	PotentialOf15mphWinds            float64 `json:"potential_of_15_mph_winds"            gridpoint:"potentialOf15mphWinds"            unit:"percent"`
+	PotentialOf25mphWinds            float64 `json:"potential_of_25_mph_winds"            gridpoint:"potentialOf25mphWinds"            unit:"percent"`
+	PotentialOf35mphWinds            float64 `json:"potential_of_35_mph_winds"            gridpoint:"potentialOf35mphWinds"            unit:"percent"`
+	PotentialOf45mphWinds            float64 `json:"potential_of_45_mph_winds"            gridpoint:"potentialOf45mphWinds"            unit:"percent"`
+	PotentialOf20mphWindGusts        float64 `json:"potential_of_20_mph_wind_gusts"       gridpoint:"potentialOf20mphWindGusts"        unit:"percent"`
+	PotentialOf30mphWindGusts        float64 `json:"potential_of_30_mph_wind_gusts"       gridpoint:"potentialOf30mphWindGusts"        unit:"percent"`
+	PotentialOf40mphWindGusts        float64 `json:"potential_of_40_mph_wind_gusts"       gridpoint:"potentialOf40mphWindGusts"        unit:"percent"`
+	PotentialOf50mphWindGusts        float64 `json:"potential_of_50_mph_wind_gusts"       gridpoint:"potentialOf50mphWindGusts"        unit:"percent"`
+	PotentialOf60mphWindGusts        float64 `json:"potential_of_60_mph_wind_gusts"       gridpoint:"potentialOf60mphWindGusts"        unit:"percent"`
+	GrasslandFireDangerIndex         float64 `json:"grassland_fire_danger_index"          gridpoint:"grasslandFireDangerIndex"`
+	ProbabilityOfThunder             float64 `json:"probability_of_thunder"               gridpoint:"probabilityOfThunder"             unit:"percent"`
+	DavisStabilityIndex              float64 `json:"davis_stability_index"                gridpoint:"davisStabilityIndex"`
+	AtmosphericDispersionIndex       float64 `json:"atmospheric_dispersion_index"         gridpoint:"atmosphericDispersionIndex"`
+	LowVisibilityOccurrenceRiskIndex float64 `json:"low_visibility_occurrence_risk_index" gridpoint:"lowVisibilityOccurrenceRiskIndex"`
+	Stability                        float64 `json:"stability"                            gridpoint:"stability"`
+	RedFlagThreatIndex               float64 `json:"red_flag_threat_index"                gridpoint:"redFlagThreatIndex"`
+
+	// imperial columns are only populated when requested (see SetImperial)
+
+	TemperatureF                *float64 `json:"temperature_degf"`
+	DewpointF                   *float64 `json:"dewpoint_degf"`
+	MaxTemperatureF             *float64 `json:"max_temperature_degf"`
+	MinTemperatureF             *float64 `json:"min_temperature_degf"`
+	ApparentTemperatureF        *float64 `json:"apparent_temperature_degf"`
+	HeatIndexF                  *float64 `json:"heat_index_degf"`
+	WindChillF                  *float64 `json:"wind_chill_degf"`
+	WindSpeedMph                *float64 `json:"wind_speed_mph"`
+	WindGustMph                 *float64 `json:"wind_gust_mph"`
+	QuantitativePrecipitationIn *float64 `json:"precipitation_quantity_in"`
+	IceAccumulationIn           *float64 `json:"ice_accumulation_in"`
+	SnowfallAmountIn            *float64 `json:"snowfall_amount_in"`
+}
+
+// SetImperial populates the imperial columns from their metric counterparts.
+func (w *Weather) SetImperial() {
+	convert := func(value float64, from, to units.Unit) *float64 {
+		// conversions between units of the same dimension never fail
+		converted, _ := units.Convert(value, from, to)
+		return &converted
+	}
+
+	w.TemperatureF = convert(w.Temperature, units.Celsius, units.Fahrenheit)
+	w.DewpointF = convert(w.Dewpoint, units.Celsius, units.Fahrenheit)
+	w.MaxTemperatureF = convert(w.MaxTemperature, units.Celsius, units.Fahrenheit)
+	w.MinTemperatureF = convert(w.MinTemperature, units.Celsius, units.Fahrenheit)
+	w.ApparentTemperatureF = convert(w.ApparentTemperature, units.Celsius, units.Fahrenheit)
+	w.HeatIndexF = convert(w.HeatIndex, units.Celsius, units.Fahrenheit)
+	w.WindChillF = convert(w.WindChill, units.Celsius, units.Fahrenheit)
+	w.WindSpeedMph = convert(w.WindSpeed, units.KilometersPerHour, units.MilesPerHour)
+	w.WindGustMph = convert(w.WindGust, units.KilometersPerHour, units.MilesPerHour)
+	w.QuantitativePrecipitationIn = convert(w.QuantitativePrecipitation, units.Millimeters, units.Inches)
+	w.IceAccumulationIn = convert(w.IceAccumulation, units.Millimeters, units.Inches)
+	w.SnowfallAmountIn = convert(w.SnowfallAmount, units.Millimeters, units.Inches)
+}
+
+func (w Weather) TableName() string {
+	return "weather"
+}
+
+func (w Weather) NaturalKey() []string {
+	return []string{"location", "timestamp"}
+}
+
+func (w Weather) RevisionKey() string {
+	return "observed_at"
+}
+
+func (w Weather) TimeKey() string {
+	return "timestamp"
+}
+
+func (w Weather) LocationKey() string {
+	return "location"
+}
+
+func (w Weather) Defaults() map[string]interface{} {
+	return map[string]interface{}{"location": DefaultLocation}
+}
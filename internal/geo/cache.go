@@ -0,0 +1,83 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/state"
+	"github.com/mjpitz/myago/clocks"
+)
+
+// Cache remembers resolved locations using a state.Store so that later runs can skip looking them up again. Entries
+// are reused until they are older than the TTL. A TTL of zero disables the cache, while Refresh resolves every entry
+// again and replaces what was stored.
+type Cache struct {
+	Store   *state.Store
+	TTL     time.Duration
+	Refresh bool
+}
+
+type cacheEntry struct {
+	ResolvedAt time.Time       `json:"resolved_at"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// Lookup decodes the value stored under key into v. When the value is missing, expired, or being refreshed, resolve
+// is called to populate v and the result is stored for later runs.
+func (c *Cache) Lookup(ctx context.Context, key string, v interface{}, resolve func() error) error {
+	now := clocks.Extract(ctx).Now()
+	enabled := c.Store != nil && c.TTL > 0
+
+	if enabled && !c.Refresh {
+		entry := cacheEntry{}
+		ok, err := c.Store.Get(key, &entry)
+
+		// entries that can no longer be decoded are resolved again
+		if err == nil && ok && now.Before(entry.ResolvedAt.Add(c.TTL)) && json.Unmarshal(entry.Value, v) == nil {
+			return nil
+		}
+	}
+
+	err := resolve()
+	if err != nil || !enabled {
+		return err
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.Store.Set(key, cacheEntry{ResolvedAt: now, Value: value})
+}
+
+// Locate behaves like the package level Locate, but reuses addresses that were previously geocoded.
+func (c *Cache) Locate(ctx context.Context, client *geocoding.Client, coordinates string, address geocoding.Address) (*Place, error) {
+	if coordinates != "" || Empty(address) {
+		return Locate(ctx, client, coordinates, address)
+	}
+
+	key := "locations/geocode/" + strings.ToLower(strings.Join([]string{
+		address.Street, address.City, address.State, address.Zip,
+	}, "|"))
+
+	place := &Place{}
+	err := c.Lookup(ctx, key, place, func() error {
+		geocoded, err := Geocode(ctx, client, address)
+		if err != nil {
+			return err
+		}
+
+		*place = *geocoded
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return place, nil
+}
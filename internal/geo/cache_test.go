@@ -0,0 +1,63 @@
+package geo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/geo"
+	"github.com/mjpitz/homestead/internal/state"
+	"github.com/mjpitz/myago/clocks"
+)
+
+func TestCache(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	ctx := clocks.ToContext(context.Background(), clock)
+
+	client, calls := geocoder(t, `{"result":{"addressMatches":[
+		{"matchedAddress": "1 MAIN ST, TOPEKA, KS, 66603", "coordinates": {"x": -95.6752, "y": 39.0473}}
+	]}}`)
+
+	store, err := state.Open(state.Config{})
+	require.NoError(t, err)
+
+	cache := &geo.Cache{Store: store, TTL: time.Hour}
+	address := geocoding.Address{Street: "1 Main St", City: "Topeka", State: "KS"}
+
+	locate := func() *geo.Place {
+		place, err := cache.Locate(ctx, client, "", address)
+		require.NoError(t, err)
+		return place
+	}
+
+	place := locate()
+	require.Equal(t, "1 MAIN ST, TOPEKA, KS, 66603", place.Address)
+	require.Equal(t, 1, *calls)
+
+	// served from the store until the entry expires
+	clock.Advance(30 * time.Minute)
+	require.Equal(t, place, locate())
+	require.Equal(t, 1, *calls)
+
+	clock.Advance(time.Hour)
+	locate()
+	require.Equal(t, 2, *calls)
+
+	cache.Refresh = true
+	locate()
+	require.Equal(t, 3, *calls)
+
+	// coordinates never need to be cached
+	_, err = cache.Locate(ctx, client, "39.0473,-95.6752", address)
+	require.NoError(t, err)
+	require.Equal(t, 3, *calls)
+
+	cache = &geo.Cache{Store: store}
+	locate()
+	locate()
+	require.Equal(t, 5, *calls)
+}
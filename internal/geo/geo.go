@@ -0,0 +1,92 @@
+package geo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Point is a location on the earth in decimal degrees.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("%.6f,%.6f", p.Latitude, p.Longitude)
+}
+
+// Parse reads a point from a comma separated latitude and longitude (e.g. 39.0473,-95.6752), a geo URI as described by
+// RFC 5870 (e.g. geo:39.0473,-95.6752), or a full plus code (e.g. 86C6236X+W2).
+func Parse(value string) (Point, error) {
+	value = strings.TrimSpace(value)
+
+	switch {
+	case value == "":
+		return Point{}, fmt.Errorf("no coordinates provided")
+	case strings.HasPrefix(strings.ToLower(value), "geo:"):
+		return parseURI(value)
+	case strings.Contains(value, "+"):
+		return DecodePlusCode(value)
+	}
+
+	return parsePair(value)
+}
+
+// parseURI reads the latitude and longitude from a geo URI, ignoring any altitude or parameters.
+func parseURI(value string) (Point, error) {
+	coordinates := value[len("geo:"):]
+
+	// parameters such as the coordinate reference system and uncertainty follow a semicolon
+	params := ""
+	if i := strings.Index(coordinates, ";"); i >= 0 {
+		coordinates, params = coordinates[:i], coordinates[i+1:]
+	}
+
+	for _, param := range strings.Split(params, ";") {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 && strings.EqualFold(kv[0], "crs") &&
+			!strings.EqualFold(kv[1], "wgs84") {
+			return Point{}, fmt.Errorf("unsupported coordinate reference system: %s", kv[1])
+		}
+	}
+
+	parts := strings.Split(coordinates, ",")
+	if len(parts) == 3 {
+		parts = parts[:2]
+	}
+
+	return parsePair(strings.Join(parts, ","))
+}
+
+func parsePair(value string) (Point, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return Point{}, fmt.Errorf("invalid coordinates %q: expected latitude,longitude", value)
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid latitude %q", parts[0])
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid longitude %q", parts[1])
+	}
+
+	point := Point{Latitude: latitude, Longitude: longitude}
+
+	return point, point.Validate()
+}
+
+// Validate ensures the point lies within the range of valid latitudes and longitudes.
+func (p Point) Validate() error {
+	switch {
+	case p.Latitude < -90 || p.Latitude > 90:
+		return fmt.Errorf("latitude %f is not between -90 and 90", p.Latitude)
+	case p.Longitude < -180 || p.Longitude > 180:
+		return fmt.Errorf("longitude %f is not between -180 and 180", p.Longitude)
+	}
+
+	return nil
+}
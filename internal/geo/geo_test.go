@@ -0,0 +1,80 @@
+package geo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/geo"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		latitude  float64
+		longitude float64
+		err       string
+	}{
+		{name: "pair", value: "39.0473,-95.6752", latitude: 39.0473, longitude: -95.6752},
+		{name: "pair with spaces", value: " 39.0473, -95.6752 ", latitude: 39.0473, longitude: -95.6752},
+		{name: "geo uri", value: "geo:39.0473,-95.6752", latitude: 39.0473, longitude: -95.6752},
+		{name: "geo uri with altitude", value: "geo:39.0473,-95.6752,320", latitude: 39.0473, longitude: -95.6752},
+		{name: "geo uri with parameters", value: "GEO:39.0473,-95.6752;crs=wgs84;u=30", latitude: 39.0473, longitude: -95.6752},
+		{name: "plus code", value: "7FG49QCJ+2V", latitude: 20.3700625, longitude: 2.7821875},
+		{name: "empty", value: "", err: "no coordinates provided"},
+		{name: "single value", value: "39.0473", err: "expected latitude,longitude"},
+		{name: "invalid latitude", value: "north,-95.6752", err: "invalid latitude"},
+		{name: "out of range", value: "91,-95.6752", err: "latitude 91.000000 is not between -90 and 90"},
+		{name: "unsupported crs", value: "geo:39.0473,-95.6752;crs=nad27", err: "unsupported coordinate reference system"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			point, err := geo.Parse(testCase.value)
+			if testCase.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), testCase.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.InDelta(t, testCase.latitude, point.Latitude, 1e-9)
+			require.InDelta(t, testCase.longitude, point.Longitude, 1e-9)
+		})
+	}
+}
+
+func TestDecodePlusCode(t *testing.T) {
+	testCases := []struct {
+		code      string
+		latitude  float64
+		longitude float64
+		err       string
+	}{
+		// centers of the areas from the Open Location Code test data
+		{code: "7FG49Q00+", latitude: 20.375, longitude: 2.775},
+		{code: "7fg49qcj+2v", latitude: 20.3700625, longitude: 2.7821875},
+		{code: "7FG49QCJ+2VX", latitude: 20.3701125, longitude: 2.782234375},
+		{code: "9QCJ+2V", err: "short plus code"},
+		{code: "7FG49QCJ+2", err: "invalid plus code"},
+		{code: "7FG4900J+2V", err: "invalid plus code"},
+		{code: "7FG49QCA+2V", err: "unexpected character"},
+		{code: "XFG49QCJ+2V", err: "out of range"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.code, func(t *testing.T) {
+			point, err := geo.DecodePlusCode(testCase.code)
+			if testCase.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), testCase.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.InDelta(t, testCase.latitude, point.Latitude, 1e-9)
+			require.InDelta(t, testCase.longitude, point.Longitude, 1e-9)
+		})
+	}
+}
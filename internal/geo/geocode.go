@@ -0,0 +1,105 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+)
+
+// Place is a point along with the address it was geocoded from, if any.
+type Place struct {
+	Point
+	// Address is the address as matched by the geocoder. It is empty when coordinates were provided directly.
+	Address string `json:"address"`
+}
+
+// AmbiguousError is returned when an address does not match exactly one location.
+type AmbiguousError struct {
+	Address    geocoding.Address
+	Candidates []string
+}
+
+func (e *AmbiguousError) Error() string {
+	address := strings.Join(nonEmpty(e.Address.Street, e.Address.City, e.Address.State, e.Address.Zip), ", ")
+
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("no matches found for address %q, try providing coordinates instead", address)
+	}
+
+	return fmt.Sprintf("address %q is ambiguous, use a more specific address or provide coordinates: matched %s",
+		address, strings.Join(e.Candidates, "; "))
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// Empty returns true when no part of the address has been provided.
+func Empty(address geocoding.Address) bool {
+	return len(nonEmpty(address.Street, address.City, address.State, address.Zip)) == 0
+}
+
+// Geocode resolves the address using the Census geocoder. An *AmbiguousError is returned unless the address matches a
+// single location. Matches that only differ by their TIGER/Line side are considered the same location.
+func Geocode(ctx context.Context, client *geocoding.Client, address geocoding.Address) (*Place, error) {
+	resp, err := client.SearchByAddress(ctx, &address)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*geocoding.AddressMatch, 0)
+	candidates := make([]string, 0)
+	seen := make(map[string]bool)
+
+	if resp.Result != nil {
+		for _, match := range resp.Result.AddressMatches {
+			if match == nil || match.Coordinates == nil || seen[match.MatchedAddress] {
+				continue
+			}
+
+			seen[match.MatchedAddress] = true
+			matches = append(matches, match)
+			candidates = append(candidates, fmt.Sprintf("%s (%.4f,%.4f)",
+				match.MatchedAddress, match.Coordinates.Y, match.Coordinates.X))
+		}
+	}
+
+	if len(matches) != 1 {
+		return nil, &AmbiguousError{Address: address, Candidates: candidates}
+	}
+
+	return &Place{
+		Point: Point{
+			Latitude:  float64(matches[0].Coordinates.Y),
+			Longitude: float64(matches[0].Coordinates.X),
+		},
+		Address: matches[0].MatchedAddress,
+	}, nil
+}
+
+// Locate returns the place described by the coordinates, falling back to geocoding the address when no coordinates
+// are provided. The geocoder is only contacted when an address is given.
+func Locate(ctx context.Context, client *geocoding.Client, coordinates string, address geocoding.Address) (*Place, error) {
+	switch {
+	case coordinates != "":
+		point, err := Parse(coordinates)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Place{Point: point}, nil
+	case Empty(address):
+		return nil, fmt.Errorf("either coordinates or an address must be provided")
+	}
+
+	return Geocode(ctx, client, address)
+}
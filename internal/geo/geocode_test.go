@@ -0,0 +1,87 @@
+package geo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/apis/geocoding"
+	"github.com/mjpitz/homestead/internal/geo"
+)
+
+func geocoder(t *testing.T, body string) (*geocoding.Client, *int) {
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := geocoding.NewClient(server.Client())
+	client.BaseURL = server.URL
+
+	return client, &calls
+}
+
+func TestLocate(t *testing.T) {
+	ctx := context.Background()
+	address := geocoding.Address{Street: "1 Main St", City: "Topeka", State: "KS"}
+
+	t.Run("coordinates", func(t *testing.T) {
+		client, calls := geocoder(t, `{}`)
+
+		place, err := geo.Locate(ctx, client, "geo:39.0473,-95.6752", address)
+		require.NoError(t, err)
+		require.Equal(t, 39.0473, place.Latitude)
+		require.Equal(t, "", place.Address)
+		require.Equal(t, 0, *calls)
+	})
+
+	t.Run("single match", func(t *testing.T) {
+		client, _ := geocoder(t, `{"result":{"addressMatches":[
+			{"matchedAddress": "1 MAIN ST, TOPEKA, KS, 66603", "coordinates": {"x": -95.6752, "y": 39.0473}},
+			{"matchedAddress": "1 MAIN ST, TOPEKA, KS, 66603", "coordinates": {"x": -95.6752, "y": 39.0473}}
+		]}}`)
+
+		place, err := geo.Locate(ctx, client, "", address)
+		require.NoError(t, err)
+		require.Equal(t, "1 MAIN ST, TOPEKA, KS, 66603", place.Address)
+		require.InDelta(t, -95.6752, place.Longitude, 1e-4)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		client, _ := geocoder(t, `{"result":{"addressMatches":[]}}`)
+
+		_, err := geo.Locate(ctx, client, "", address)
+
+		ambiguous := &geo.AmbiguousError{}
+		require.True(t, errors.As(err, &ambiguous))
+		require.Empty(t, ambiguous.Candidates)
+		require.Contains(t, err.Error(), `no matches found for address "1 Main St, Topeka, KS"`)
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		client, _ := geocoder(t, `{"result":{"addressMatches":[
+			{"matchedAddress": "1 MAIN ST, TOPEKA, KS, 66603", "coordinates": {"x": -95.6752, "y": 39.0473}},
+			{"matchedAddress": "1 MAIN ST, TOPEKA, KS, 66611", "coordinates": {"x": -95.7, "y": 39.01}}
+		]}}`)
+
+		_, err := geo.Locate(ctx, client, "", address)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "1 MAIN ST, TOPEKA, KS, 66603 (39.0473,-95.6752)")
+		require.Contains(t, err.Error(), "1 MAIN ST, TOPEKA, KS, 66611")
+	})
+
+	t.Run("nothing provided", func(t *testing.T) {
+		client, calls := geocoder(t, `{}`)
+
+		_, err := geo.Locate(ctx, client, "", geocoding.Address{})
+		require.Error(t, err)
+		require.Equal(t, 0, *calls)
+	})
+}
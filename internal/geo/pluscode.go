@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	plusCodeAlphabet  = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator = 8 // position of the '+' within a full code
+	plusCodePairs     = 10
+	plusCodeMaxDigits = 15
+)
+
+// DecodePlusCode returns the center of the area described by a full Open Location Code (plus code). Short codes, such
+// as "36X+W2 Topeka", depend on a nearby reference location and are not supported.
+func DecodePlusCode(code string) (Point, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	separator := strings.Index(code, "+")
+	switch {
+	case separator < 0 || separator != strings.LastIndex(code, "+"):
+		return Point{}, fmt.Errorf("invalid plus code %q", code)
+	case separator < plusCodeSeparator:
+		return Point{}, fmt.Errorf("short plus code %q is not supported, use the full code instead", code)
+	case separator > plusCodeSeparator:
+		return Point{}, fmt.Errorf("invalid plus code %q", code)
+	}
+
+	digits := code[:separator] + code[separator+1:]
+
+	// padding may only be used to shorten codes to a whole number of pairs before the separator
+	if padding := strings.Index(digits, "0"); padding >= 0 {
+		if padding == 0 || padding%2 != 0 || strings.TrimRight(digits[padding:], "0") != "" ||
+			len(code) != separator+1 {
+			return Point{}, fmt.Errorf("invalid plus code %q", code)
+		}
+
+		digits = digits[:padding]
+	} else if len(code)-separator-1 == 1 {
+		return Point{}, fmt.Errorf("invalid plus code %q", code)
+	}
+
+	if len(digits) > plusCodeMaxDigits {
+		digits = digits[:plusCodeMaxDigits]
+	}
+
+	values := make([]int, len(digits))
+	for i, r := range digits {
+		values[i] = strings.IndexRune(plusCodeAlphabet, r)
+		if values[i] < 0 {
+			return Point{}, fmt.Errorf("invalid plus code %q: unexpected character %q", code, r)
+		}
+	}
+
+	// the first pair cannot exceed 90 degrees of latitude or 180 degrees of longitude
+	if values[0]*20 >= 180 || (len(values) > 1 && values[1]*20 >= 360) {
+		return Point{}, fmt.Errorf("invalid plus code %q: out of range", code)
+	}
+
+	latitude, longitude := -90.0, -180.0
+	latitudeSize, longitudeSize := 400.0, 400.0
+
+	for i := 0; i < len(values) && i < plusCodePairs; i += 2 {
+		latitudeSize /= 20
+		longitudeSize /= 20
+
+		latitude += float64(values[i]) * latitudeSize
+		if i+1 < len(values) {
+			longitude += float64(values[i+1]) * longitudeSize
+		}
+	}
+
+	// digits beyond the pairs refine the area using a grid of 4 columns and 5 rows
+	for i := plusCodePairs; i < len(values); i++ {
+		latitudeSize /= 5
+		longitudeSize /= 4
+
+		latitude += float64(values[i]/4) * latitudeSize
+		longitude += float64(values[i]%4) * longitudeSize
+	}
+
+	return Point{
+		Latitude:  latitude + latitudeSize/2,
+		Longitude: longitude + longitudeSize/2,
+	}, nil
+}
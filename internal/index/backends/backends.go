@@ -0,0 +1,12 @@
+// Package backends registers every available index backend. Commands that build an index should import this package
+// for its side effects so that any supported endpoint scheme can be used.
+package backends
+
+import (
+	_ "github.com/mjpitz/homestead/internal/index/file"
+	_ "github.com/mjpitz/homestead/internal/index/influxdb"
+	_ "github.com/mjpitz/homestead/internal/index/memory"
+	_ "github.com/mjpitz/homestead/internal/index/postgres"
+	_ "github.com/mjpitz/homestead/internal/index/prometheus"
+	_ "github.com/mjpitz/homestead/internal/index/sqlite"
+)
@@ -1,18 +1,18 @@
-package postgres
+package index
 
 import (
 	"context"
 
-	"github.com/mjpitz/homestead/internal/index"
 	"github.com/mjpitz/myago/zaputil"
 )
 
+// Builder opens the index configured by an endpoint and runs an action against it.
 type Builder struct {
-	Action func(ctx context.Context, index index.Index) error
+	Action func(ctx context.Context, index Index) error
 }
 
-func (b Builder) performAction(ctx context.Context, cfg index.Config) error {
-	idx, err := Open(cfg.Endpoint)
+func (b Builder) performAction(ctx context.Context, cfg Config) error {
+	idx, err := Open(cfg)
 	if err != nil {
 		return err
 	}
@@ -29,7 +29,7 @@ func (b Builder) performAction(ctx context.Context, cfg index.Config) error {
 	return b.Action(ctx, idx)
 }
 
-func (b Builder) Run(ctx context.Context, cfg index.Config) error {
+func (b Builder) Run(ctx context.Context, cfg Config) error {
 	log := zaputil.Extract(ctx)
 
 	log.Info("updating index")
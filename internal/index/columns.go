@@ -0,0 +1,158 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Column describes a single value within a document.
+type Column struct {
+	// Name is derived from the json tag of the field, falling back to the field name.
+	Name string
+	// Type is the type of the field with any pointer removed.
+	Type reflect.Type
+	// Value holds the value of the field with any pointer removed. It is invalid when the field is a nil pointer.
+	Value reflect.Value
+	// Optional is set when the field is a pointer and may be nil.
+	Optional bool
+}
+
+func columns(value reflect.Value, cols []Column) []Column {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+
+				fieldValue = fieldValue.Elem()
+			}
+
+			if fieldValue.Kind() == reflect.Struct {
+				cols = columns(fieldValue, cols)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		col := Column{
+			Name:  name,
+			Type:  field.Type,
+			Value: fieldValue,
+		}
+
+		if field.Type.Kind() == reflect.Ptr {
+			col.Optional = true
+			col.Type = field.Type.Elem()
+			col.Value = fieldValue.Elem()
+		}
+
+		cols = append(cols, col)
+	}
+
+	return cols
+}
+
+// Columns returns the exported columns of the provided document in the order they're declared. Embedded structs are
+// flattened into the parent document.
+func Columns(doc interface{}) ([]Column, error) {
+	value := reflect.Indirect(reflect.ValueOf(doc))
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported document type: %T", doc)
+	}
+
+	return columns(value, nil), nil
+}
+
+// Table returns the name of the table (or measurement) that a document is written to. Documents that provide a
+// TableName use it, otherwise the snake cased name of the type is used.
+func Table(doc interface{}) string {
+	if tabler, ok := doc.(interface{ TableName() string }); ok {
+		return tabler.TableName()
+	}
+
+	name := reflect.Indirect(reflect.ValueOf(doc)).Type().Name()
+	out := strings.Builder{}
+
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			out.WriteByte('_')
+		}
+
+		out.WriteRune(r)
+	}
+
+	return strings.ToLower(out.String())
+}
+
+// Time returns the value of the column identified by index.TimeSeries, if the document has one.
+func Time(doc interface{}) (time.Time, bool) {
+	series, ok := doc.(TimeSeries)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	cols, err := Columns(doc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for _, col := range cols {
+		if col.Name == series.TimeKey() && col.Value.IsValid() {
+			t, ok := col.Value.Interface().(time.Time)
+			return t, ok
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Identity returns a string identifying the provided document under the configured Mode. Documents without a key
+// return an empty identity and should never replace another document.
+func (c Config) Identity(doc interface{}) (string, error) {
+	key, err := c.Key(doc)
+	if err != nil || len(key) == 0 {
+		return "", err
+	}
+
+	cols, err := Columns(doc)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		if col.Value.IsValid() {
+			values[col.Name] = col.Value.Interface()
+		}
+	}
+
+	parts := make([]string, 0, len(key))
+	for _, column := range key {
+		value := values[column]
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+
+		parts = append(parts, fmt.Sprint(value))
+	}
+
+	return strings.Join(parts, "\x00"), nil
+}
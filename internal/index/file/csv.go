@@ -0,0 +1,139 @@
+package file
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/index"
+)
+
+// columnsByName indexes the columns of the provided document by their name.
+func columnsByName(doc interface{}) (map[string]index.Column, error) {
+	cols, err := index.Columns(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]index.Column, len(cols))
+	for _, col := range cols {
+		byName[col.Name] = col
+	}
+
+	return byName, nil
+}
+
+// CSV writes documents as comma separated values with a header row derived from the json tags of the document. Times
+// are written using RFC 3339 and nil values are left empty.
+type CSV struct{}
+
+func (CSV) Extension() string {
+	return "csv"
+}
+
+func (CSV) format(col index.Column) string {
+	if !col.Value.IsValid() {
+		return ""
+	}
+
+	if t, ok := col.Value.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	switch col.Type.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(col.Value.Float(), 'f', -1, 64)
+	}
+
+	return fmt.Sprint(col.Value.Interface())
+}
+
+func (c CSV) Encode(w io.Writer, docs []interface{}) error {
+	writer := csv.NewWriter(w)
+
+	for i, doc := range docs {
+		cols, err := index.Columns(doc)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			header := make([]string, 0, len(cols))
+			for _, col := range cols {
+				header = append(header, col.Name)
+			}
+
+			err = writer.Write(header)
+			if err != nil {
+				return err
+			}
+		}
+
+		record := make([]string, 0, len(cols))
+		for _, col := range cols {
+			record = append(record, c.format(col))
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (CSV) Decode(data []byte, template interface{}) ([]map[string]interface{}, error) {
+	byName, err := columnsByName(template)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	header := records[0]
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(record))
+
+		for i, cell := range record {
+			if i >= len(header) || cell == "" {
+				continue
+			}
+
+			col, ok := byName[header[i]]
+			if !ok {
+				continue
+			}
+
+			var value interface{} = cell
+
+			switch col.Type.Kind() {
+			case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+				reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				value, err = strconv.ParseFloat(cell, 64)
+			case reflect.Bool:
+				value, err = strconv.ParseBool(cell)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", header[i], err)
+			}
+
+			row[header[i]] = value
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,275 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/myago/clocks"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+func init() {
+	index.Register(func(cfg index.Config) (index.Index, error) {
+		return Open(cfg)
+	}, "file")
+}
+
+// Format encodes and decodes documents to and from a single file.
+type Format interface {
+	// Extension returns the file extension used by the format, without the leading dot.
+	Extension() string
+	// Encode writes the provided documents, all of the same type, to a file.
+	Encode(w io.Writer, docs []interface{}) error
+	// Decode reads the rows of an existing file. Each row maps a column name to its json representation.
+	Decode(data []byte, template interface{}) ([]map[string]interface{}, error)
+}
+
+var formats = map[string]Format{
+	"ndjson":  NDJSON{},
+	"csv":     CSV{},
+	"parquet": Parquet{},
+}
+
+// Open parses an endpoint of the form file:///var/lib/homestead/export?format=parquet and returns an Index that writes
+// documents to files beneath the provided directory. Supported formats are ndjson (the default), csv, and parquet.
+func Open(cfg index.Config) (*Index, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(endpoint.Host, endpoint.Path)
+	if root == "" {
+		return nil, fmt.Errorf("missing file directory: %s", cfg.Endpoint)
+	}
+
+	name := endpoint.Query().Get("format")
+	if name == "" {
+		name = "ndjson"
+	}
+
+	format, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unrecognized file format: %s", name)
+	}
+
+	if cfg.Mode == "" {
+		cfg.Mode = index.ModeLatest
+	}
+
+	return &Index{cfg: cfg, root: root, format: format}, nil
+}
+
+// Index writes documents to files partitioned by table and date (e.g. weather/2022/01/12.parquet). Documents are
+// partitioned using the column returned by index.TimeSeries, falling back to the time they were written. Each file is
+// rewritten to a temporary file and atomically renamed into place so that a failed run never leaves a partially
+// written file behind.
+type Index struct {
+	cfg    index.Config
+	root   string
+	format Format
+}
+
+func (idx *Index) partition(doc interface{}, now time.Time) string {
+	t, ok := index.Time(doc)
+	if !ok {
+		t = now
+	}
+
+	t = t.UTC()
+
+	return filepath.Join(
+		idx.root,
+		index.Table(doc),
+		fmt.Sprintf("%04d", t.Year()),
+		fmt.Sprintf("%02d", t.Month()),
+		fmt.Sprintf("%02d.%s", t.Day(), idx.format.Extension()),
+	)
+}
+
+// load reads the documents currently stored in the provided file, if it exists.
+func (idx *Index) load(path string, template interface{}) ([]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	rows, err := idx.format.Decode(data, template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	docType := reflect.TypeOf(template)
+	if docType.Kind() == reflect.Ptr {
+		docType = docType.Elem()
+	}
+	docs := make([]interface{}, 0, len(rows))
+
+	for _, row := range rows {
+		// rows are already keyed by their json names, so round trip them through json to construct the document
+		data, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+
+		doc := reflect.New(docType).Interface()
+
+		err = json.NewDecoder(bytes.NewReader(data)).Decode(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+type byTime struct {
+	docs  []interface{}
+	times []time.Time
+}
+
+func (b byTime) Len() int           { return len(b.docs) }
+func (b byTime) Less(i, j int) bool { return b.times[i].Before(b.times[j]) }
+func (b byTime) Swap(i, j int) {
+	b.docs[i], b.docs[j] = b.docs[j], b.docs[i]
+	b.times[i], b.times[j] = b.times[j], b.times[i]
+}
+
+// merge combines the existing documents of a file with the new documents, replacing any that share the same key.
+func (idx *Index) merge(existing, docs []interface{}) ([]interface{}, error) {
+	merged := make([]interface{}, 0, len(existing)+len(docs))
+	positions := make(map[string]int)
+
+	for _, doc := range append(existing, docs...) {
+		key, err := idx.cfg.Identity(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "" {
+			merged = append(merged, doc)
+			continue
+		}
+
+		if i, ok := positions[key]; ok {
+			merged[i] = doc
+			continue
+		}
+
+		positions[key] = len(merged)
+		merged = append(merged, doc)
+	}
+
+	if _, ok := index.Time(docs[0]); ok {
+		times := make([]time.Time, len(merged))
+		for i, doc := range merged {
+			times[i], _ = index.Time(doc)
+		}
+
+		sort.Stable(byTime{merged, times})
+	}
+
+	return merged, nil
+}
+
+// write atomically replaces the file at path with the provided documents.
+func (idx *Index) write(path string, docs []interface{}) (err error) {
+	dir := filepath.Dir(path)
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	err = idx.format.Encode(tmp, docs)
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (idx *Index) Index(ctx context.Context, docs ...interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	now := clocks.Extract(ctx).Now()
+	partitions := make(map[string][]interface{})
+	paths := make([]string, 0)
+
+	for _, doc := range docs {
+		path := idx.partition(doc, now)
+
+		if _, ok := partitions[path]; !ok {
+			paths = append(paths, path)
+		}
+
+		partitions[path] = append(partitions[path], doc)
+	}
+
+	for _, path := range paths {
+		batch := partitions[path]
+
+		existing, err := idx.load(path, batch[0])
+		if err != nil {
+			return err
+		}
+
+		merged, err := idx.merge(existing, batch)
+		if err != nil {
+			return err
+		}
+
+		err = idx.write(path, merged)
+		if err != nil {
+			return err
+		}
+	}
+
+	zaputil.Extract(ctx).Info("wrote files", zap.Int("files", len(paths)), zap.Int("docs", len(docs)))
+	return nil
+}
+
+func (idx *Index) Close() error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package file_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/file"
+)
+
+type reading struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ObservedAt  time.Time `json:"observed_at"`
+	Location    string    `json:"location"`
+	Temperature float64   `json:"temperature_degc"`
+	Imperial    *float64  `json:"temperature_degf"`
+}
+
+func (r reading) TableName() string    { return "weather" }
+func (r reading) NaturalKey() []string { return []string{"timestamp"} }
+func (r reading) RevisionKey() string  { return "observed_at" }
+func (r reading) TimeKey() string      { return "timestamp" }
+
+func TestIndex(t *testing.T) {
+	start := time.Date(2022, 1, 12, 23, 0, 0, 0, time.UTC)
+	degf := 33.8
+
+	for _, format := range []file.Format{file.NDJSON{}, file.CSV{}, file.Parquet{}} {
+		t.Run(format.Extension(), func(t *testing.T) {
+			ctx := context.Background()
+			root := t.TempDir()
+
+			idx, err := file.Open(index.Config{Endpoint: "file://" + root + "?format=" + format.Extension()})
+			require.NoError(t, err)
+
+			err = idx.Index(ctx,
+				&reading{Timestamp: start, ObservedAt: start, Location: "house", Temperature: 1, Imperial: &degf},
+				&reading{Timestamp: start.Add(time.Hour), ObservedAt: start, Location: "house", Temperature: 2},
+			)
+			require.NoError(t, err)
+
+			// a later observation replaces the reading for the same timestamp
+			err = idx.Index(ctx,
+				&reading{Timestamp: start.Add(30 * time.Minute), ObservedAt: start.Add(time.Hour), Location: "house", Temperature: 3},
+				&reading{Timestamp: start, ObservedAt: start.Add(time.Hour), Location: "house", Temperature: 4},
+			)
+			require.NoError(t, err)
+
+			day := filepath.Join(root, "weather", "2022", "01", "12."+format.Extension())
+			nextDay := filepath.Join(root, "weather", "2022", "01", "13."+format.Extension())
+
+			for path, expected := range map[string][]float64{day: {4, 3}, nextDay: {2}} {
+				data, err := ioutil.ReadFile(path)
+				require.NoError(t, err)
+
+				rows, err := format.Decode(data, &reading{})
+				require.NoError(t, err)
+				require.Len(t, rows, len(expected))
+
+				for i, row := range rows {
+					require.Equal(t, expected[i], row["temperature_degc"])
+					require.Equal(t, "house", row["location"])
+				}
+			}
+
+			entries, err := ioutil.ReadDir(filepath.Dir(day))
+			require.NoError(t, err)
+			require.Len(t, entries, 2, "temporary files should not be left behind")
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// NDJSON writes each document as a single line of JSON.
+type NDJSON struct{}
+
+func (NDJSON) Extension() string {
+	return "ndjson"
+}
+
+func (NDJSON) Encode(w io.Writer, docs []interface{}) error {
+	enc := json.NewEncoder(w)
+
+	for _, doc := range docs {
+		err := enc.Encode(doc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (NDJSON) Decode(data []byte, _ interface{}) ([]map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	rows := make([]map[string]interface{}, 0)
+
+	for dec.More() {
+		row := make(map[string]interface{})
+
+		err := dec.Decode(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,169 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+
+	"github.com/mjpitz/homestead/internal/index"
+)
+
+// Parquet writes documents as snappy compressed parquet files. The schema is derived from the columns of the document,
+// with times stored as microsecond timestamps and pointer fields stored as optional columns.
+type Parquet struct{}
+
+func (Parquet) Extension() string {
+	return "parquet"
+}
+
+// Schema returns the parquet schema definition for the provided document.
+func (Parquet) Schema(doc interface{}) (string, error) {
+	cols, err := index.Columns(doc)
+	if err != nil {
+		return "", err
+	}
+
+	schema := strings.Builder{}
+	schema.WriteString("message " + index.Table(doc) + " {\n")
+
+	for _, col := range cols {
+		var kind string
+
+		switch {
+		case col.Type == reflect.TypeOf(time.Time{}):
+			kind = "int64 %s (TIMESTAMP(MICROS, true))"
+		default:
+			switch col.Type.Kind() {
+			case reflect.Float64:
+				kind = "double %s"
+			case reflect.Float32:
+				kind = "float %s"
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				kind = "int64 %s"
+			case reflect.Bool:
+				kind = "boolean %s"
+			case reflect.String:
+				kind = "binary %s (STRING)"
+			default:
+				return "", fmt.Errorf("unsupported parquet column %s of type %s", col.Name, col.Type)
+			}
+		}
+
+		repetition := "required"
+		if col.Optional {
+			repetition = "optional"
+		}
+
+		schema.WriteString("  " + repetition + " " + fmt.Sprintf(kind, col.Name) + ";\n")
+	}
+
+	schema.WriteString("}\n")
+
+	return schema.String(), nil
+}
+
+func (p Parquet) Encode(w io.Writer, docs []interface{}) error {
+	schema, err := p.Schema(docs[0])
+	if err != nil {
+		return err
+	}
+
+	definition, err := parquetschema.ParseSchemaDefinition(schema)
+	if err != nil {
+		return err
+	}
+
+	writer := goparquet.NewFileWriter(w,
+		goparquet.WithSchemaDefinition(definition),
+		goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+		goparquet.WithCreator("homestead"),
+	)
+
+	for _, doc := range docs {
+		cols, err := index.Columns(doc)
+		if err != nil {
+			return err
+		}
+
+		row := make(map[string]interface{}, len(cols))
+
+		for _, col := range cols {
+			if !col.Value.IsValid() {
+				continue
+			}
+
+			if t, ok := col.Value.Interface().(time.Time); ok {
+				row[col.Name] = t.UnixMicro()
+				continue
+			}
+
+			switch col.Type.Kind() {
+			case reflect.Float64:
+				row[col.Name] = col.Value.Float()
+			case reflect.Float32:
+				row[col.Name] = float32(col.Value.Float())
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				row[col.Name] = col.Value.Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				row[col.Name] = int64(col.Value.Uint())
+			case reflect.Bool:
+				row[col.Name] = col.Value.Bool()
+			case reflect.String:
+				row[col.Name] = []byte(col.Value.String())
+			}
+		}
+
+		err = writer.AddData(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func (Parquet) Decode(data []byte, template interface{}) ([]map[string]interface{}, error) {
+	byName, err := columnsByName(template)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := goparquet.NewFileReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]interface{}, 0, reader.NumRows())
+
+	for {
+		row, err := reader.NextRow()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for name, value := range row {
+			switch v := value.(type) {
+			case []byte:
+				row[name] = string(v)
+			case int64:
+				if col, ok := byName[name]; ok && col.Type == reflect.TypeOf(time.Time{}) {
+					row[name] = time.UnixMicro(v).UTC().Format(time.RFC3339Nano)
+				}
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
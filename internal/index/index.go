@@ -1,9 +1,82 @@
 package index
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// ModeLatest keeps a single copy of each document, replacing it with the most recent observation.
+	ModeLatest = "latest"
+	// ModeRevisions keeps every observation of a document, replacing only exact duplicates.
+	ModeRevisions = "revisions"
+)
+
+type TimescaleConfig struct {
+	Enabled       bool          `json:"enabled"        usage:"convert time series tables into timescaledb hypertables"`
+	ChunkInterval time.Duration `json:"chunk_interval" usage:"the span of time covered by each chunk of a hypertable" default:"24h"`
+	CompressAfter time.Duration `json:"compress_after" usage:"compress chunks older than this duration (0 disables compression)"`
+	RetainFor     time.Duration `json:"retain_for"     usage:"drop chunks older than this duration (0 retains data indefinitely)"`
+}
+
 type Config struct {
-	Endpoint string `json:"endpoint" usage:"a dsn string pointing to the database where we will write our data"`
+	Endpoint  string `json:"endpoint"   usage:"a dsn string pointing to the database where we will write our data (the scheme selects the backend)"`
+	Mode      string `json:"mode"       usage:"how repeated documents are written (latest, revisions)" default:"latest"`
+	BatchSize int    `json:"batch_size" usage:"the maximum number of documents written in a single statement" default:"500"`
+
+	Timescale TimescaleConfig `json:"timescale"`
+}
+
+// Key returns the set of columns that uniquely identify the provided document given the configured Mode. If the
+// document does not implement Document, then no key is returned and the document is always appended.
+func (c Config) Key(doc interface{}) ([]string, error) {
+	document, ok := doc.(Document)
+	if !ok {
+		return nil, nil
+	}
+
+	key := document.NaturalKey()
+
+	switch c.Mode {
+	case "", ModeLatest:
+		return key, nil
+	case ModeRevisions:
+		if revision := document.RevisionKey(); revision != "" {
+			return append(key, revision), nil
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unrecognized mode: %s", c.Mode)
+}
+
+// Document describes how a document is identified within an index.
+type Document interface {
+	// NaturalKey returns the columns that identify a single document, regardless of when it was observed.
+	NaturalKey() []string
+	// RevisionKey returns the column that distinguishes multiple observations of the same document. Documents that are
+	// only ever stored once, such as those referenced by other tables, return an empty string.
+	RevisionKey() string
+}
+
+// TimeSeries is implemented by documents that describe a point in time.
+type TimeSeries interface {
+	// TimeKey returns the column containing the time that the document describes.
+	TimeKey() string
+}
+
+// Defaulted is implemented by documents that gained columns after rows may have already been written. Backends that
+// migrate existing data assign the default to any row that is missing a value.
+type Defaulted interface {
+	// Defaults returns the value assigned to each column for rows written before the column existed.
+	Defaults() map[string]interface{}
 }
 
 type Index interface {
-	Index(docs ...interface{}) error
+	io.Closer
+
+	Index(ctx context.Context, docs ...interface{}) error
 }
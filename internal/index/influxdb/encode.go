@@ -0,0 +1,192 @@
+package influxdb
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/index"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	measurementEscaper = strings.NewReplacer(",", "\\,", " ", "\\ ", "\n", "\\n")
+	keyEscaper         = strings.NewReplacer(",", "\\,", "=", "\\=", " ", "\\ ", "\n", "\\n")
+	stringEscaper      = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+type pair struct {
+	key   string
+	value string
+}
+
+// Encoder converts documents into InfluxDB line protocol. The natural key of an index.Document is written as tags,
+// all other columns are written as fields, and the column returned by index.TimeSeries is used as the timestamp of the
+// point. Column names are derived from the json tags of the document.
+type Encoder struct {
+	// Tags lists additional columns that should be written as tags instead of fields.
+	Tags []string
+	// Mode is the index mode. When using index.ModeRevisions, the revision column of each document is also written as
+	// a tag so that every revision is retained.
+	Mode string
+}
+
+// tagsOf returns the columns of the document that are written as tags.
+func (e Encoder) tagsOf(doc interface{}) map[string]bool {
+	tags := make(map[string]bool, len(e.Tags))
+	for _, tag := range e.Tags {
+		tags[tag] = true
+	}
+
+	if document, ok := doc.(index.Document); ok {
+		for _, column := range document.NaturalKey() {
+			tags[column] = true
+		}
+
+		if revision := document.RevisionKey(); revision != "" && e.Mode == index.ModeRevisions {
+			tags[revision] = true
+		}
+	}
+
+	return tags
+}
+
+func (e Encoder) collect(doc interface{}, timeKey string, tags, fields *[]pair, timestamp *time.Time) error {
+	cols, err := index.Columns(doc)
+	if err != nil {
+		return err
+	}
+
+	isTag := e.tagsOf(doc)
+
+	for _, col := range cols {
+		if !col.Value.IsValid() {
+			continue
+		}
+
+		if col.Type == timeType {
+			t := col.Value.Interface().(time.Time)
+
+			switch {
+			case col.Name == timeKey:
+				*timestamp = t
+			case t.IsZero():
+				// zero times are omitted entirely
+			case isTag[col.Name]:
+				*tags = append(*tags, pair{col.Name, t.UTC().Format(time.RFC3339)})
+			default:
+				*fields = append(*fields, pair{col.Name, strconv.FormatInt(t.UnixNano(), 10) + "i"})
+			}
+
+			continue
+		}
+
+		switch col.Type.Kind() {
+		case reflect.String:
+			switch v := col.Value.String(); {
+			case v == "":
+				// empty strings are omitted entirely
+			case isTag[col.Name]:
+				*tags = append(*tags, pair{col.Name, v})
+			default:
+				*fields = append(*fields, pair{col.Name, `"` + stringEscaper.Replace(v) + `"`})
+			}
+		case reflect.Float32, reflect.Float64:
+			v := col.Value.Float()
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+
+			*fields = append(*fields, pair{col.Name, strconv.FormatFloat(v, 'f', -1, 64)})
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			*fields = append(*fields, pair{col.Name, strconv.FormatInt(col.Value.Int(), 10) + "i"})
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			*fields = append(*fields, pair{col.Name, strconv.FormatUint(col.Value.Uint(), 10) + "u"})
+		case reflect.Bool:
+			*fields = append(*fields, pair{col.Name, strconv.FormatBool(col.Value.Bool())})
+		}
+	}
+
+	return nil
+}
+
+// Line converts a single document into a line of line protocol, without the trailing newline.
+func (e Encoder) Line(doc interface{}) (string, error) {
+	timeKey := ""
+	if series, ok := doc.(index.TimeSeries); ok {
+		timeKey = series.TimeKey()
+	}
+
+	tags := make([]pair, 0)
+	fields := make([]pair, 0)
+	timestamp := time.Time{}
+
+	err := e.collect(doc, timeKey, &tags, &fields, &timestamp)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fields) == 0 {
+		return "", fmt.Errorf("document has no fields: %T", doc)
+	}
+
+	// tags should be sorted by key for the best write performance
+	sort.Slice(tags, func(i, j int) bool { return tags[i].key < tags[j].key })
+
+	line := strings.Builder{}
+	line.WriteString(measurementEscaper.Replace(index.Table(doc)))
+
+	for _, tag := range tags {
+		line.WriteByte(',')
+		line.WriteString(keyEscaper.Replace(tag.key))
+		line.WriteByte('=')
+		line.WriteString(keyEscaper.Replace(tag.value))
+	}
+
+	for i, field := range fields {
+		if i == 0 {
+			line.WriteByte(' ')
+		} else {
+			line.WriteByte(',')
+		}
+
+		line.WriteString(keyEscaper.Replace(field.key))
+		line.WriteByte('=')
+		line.WriteString(field.value)
+	}
+
+	if !timestamp.IsZero() {
+		line.WriteByte(' ')
+		line.WriteString(strconv.FormatInt(timestamp.UnixNano(), 10))
+	}
+
+	return line.String(), nil
+}
+
+// Encode writes each document to the provided writer as a line of line protocol.
+func (e Encoder) Encode(w io.Writer, docs ...interface{}) error {
+	for _, doc := range docs {
+		line, err := e.Line(doc)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, line+"\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Encode writes each document to the provided writer using the default Encoder.
+func Encode(w io.Writer, docs ...interface{}) error {
+	return Encoder{}.Encode(w, docs...)
+}
@@ -0,0 +1,179 @@
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/homestead/internal/apis/transport"
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+func init() {
+	index.Register(func(cfg index.Config) (index.Index, error) {
+		return Open(cfg)
+	}, "influx", "influxs")
+}
+
+// Open parses an endpoint of the form influx://:token@host:8086/bucket?org=homestead and returns an Index that writes
+// to the InfluxDB v2 HTTP write API. The influxs scheme connects using TLS. Requests are gzip compressed unless the
+// endpoint sets gzip=false, and each attempt is abandoned after timeout (default 30s) before being retried.
+func Open(cfg index.Config) (*Index, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := strings.Trim(endpoint.Path, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("missing influxdb bucket: %s", endpoint.Redacted())
+	}
+
+	query := endpoint.Query()
+
+	write := url.URL{
+		Scheme: "http",
+		Host:   endpoint.Host,
+		Path:   "/api/v2/write",
+		RawQuery: url.Values{
+			"org":       []string{query.Get("org")},
+			"bucket":    []string{bucket},
+			"precision": []string{"ns"},
+		}.Encode(),
+	}
+
+	if endpoint.Scheme == "influxs" {
+		write.Scheme = "https"
+	}
+
+	timeout := 30 * time.Second
+	if value := query.Get("timeout"); value != "" {
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token := ""
+	if endpoint.User != nil {
+		token = endpoint.User.Username()
+		if password, ok := endpoint.User.Password(); ok {
+			token = password
+		}
+	}
+
+	if cfg.Mode == "" {
+		cfg.Mode = index.ModeLatest
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 5000
+	}
+
+	return &Index{
+		cfg:    cfg,
+		Client: transport.NewClient(transport.Config{Timeout: timeout, Retry: transport.DefaultRetry}),
+		target: write.String(),
+		token:  token,
+		gzip:   query.Get("gzip") != "false",
+	}, nil
+}
+
+// Index writes documents to InfluxDB. Points are identified by their measurement, tags, and timestamp, so repeated
+// writes replace earlier values. When using index.ModeRevisions, the revision column of each document is written as a
+// tag so that every revision is retained.
+type Index struct {
+	cfg    index.Config
+	Client *http.Client
+	target string
+	token  string
+	gzip   bool
+}
+
+func (idx *Index) write(ctx context.Context, encoder Encoder, docs []interface{}) error {
+	body := bytes.NewBuffer(nil)
+
+	var w io.Writer = body
+	var gz *gzip.Writer
+
+	if idx.gzip {
+		gz = gzip.NewWriter(body)
+		w = gz
+	}
+
+	err := encoder.Encode(w, docs...)
+	if err != nil {
+		return err
+	}
+
+	if gz != nil {
+		err = gz.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, idx.target, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if idx.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	if idx.token != "" {
+		req.Header.Set("Authorization", "Token "+idx.token)
+	}
+
+	resp, err := idx.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("influxdb write failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+func (idx *Index) Index(ctx context.Context, docs ...interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	encoder := Encoder{Mode: idx.cfg.Mode}
+
+	for start := 0; start < len(docs); start += idx.cfg.BatchSize {
+		end := start + idx.cfg.BatchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+
+		err := idx.write(ctx, encoder, docs[start:end])
+		if err != nil {
+			return err
+		}
+	}
+
+	zaputil.Extract(ctx).Info("wrote points", zap.Int("points", len(docs)))
+	return nil
+}
+
+func (idx *Index) Close() error {
+	return nil
+}
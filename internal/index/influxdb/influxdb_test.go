@@ -0,0 +1,175 @@
+package influxdb_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/influxdb"
+)
+
+type reading struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ObservedAt  time.Time `json:"observed_at"`
+	Location    string    `json:"location"`
+	Conditions  string    `json:"conditions"`
+	Temperature float64   `json:"temperature_degc"`
+	Humidity    float64   `json:"relative_humidity_pct"`
+}
+
+func (r reading) TableName() string    { return "weather" }
+func (r reading) NaturalKey() []string { return []string{"location", "timestamp"} }
+func (r reading) RevisionKey() string  { return "observed_at" }
+func (r reading) TimeKey() string      { return "timestamp" }
+
+var (
+	timestamp  = time.Date(2022, 1, 12, 15, 0, 0, 0, time.UTC)
+	observedAt = time.Date(2022, 1, 12, 12, 0, 0, 0, time.UTC)
+)
+
+func TestEncode(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	err := influxdb.Encode(buf, &reading{
+		Timestamp:   timestamp,
+		ObservedAt:  observedAt,
+		Location:    "back pasture",
+		Conditions:  `light "freezing" rain`,
+		Temperature: -1.5,
+		Humidity:    80,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"weather,location=back\\ pasture observed_at=1641988800000000000i,conditions=\"light \\\"freezing\\\" rain\",temperature_degc=-1.5,relative_humidity_pct=80 1641999600000000000\n",
+		buf.String(),
+	)
+}
+
+func TestIndex(t *testing.T) {
+	var body string
+	var query string
+	var auth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/write", r.URL.Path)
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(gz)
+		require.NoError(t, err)
+
+		body += string(data)
+		query = r.URL.RawQuery
+		auth = r.Header.Get("Authorization")
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	endpoint := strings.Replace(server.URL, "http://", "influx://:secret@", 1) + "/homestead?org=home"
+
+	idx, err := index.Open(index.Config{Endpoint: endpoint, Mode: index.ModeRevisions, BatchSize: 1})
+	require.NoError(t, err)
+	defer idx.Close()
+
+	err = idx.Index(context.Background(),
+		&reading{Timestamp: timestamp, ObservedAt: observedAt, Location: "house", Temperature: 1},
+		&reading{Timestamp: timestamp.Add(time.Hour), ObservedAt: observedAt, Location: "house", Temperature: 2},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, "Token secret", auth)
+	require.Equal(t, "bucket=homestead&org=home&precision=ns", query)
+	require.Equal(t, strings.Join([]string{
+		"weather,location=house,observed_at=2022-01-12T12:00:00Z temperature_degc=1,relative_humidity_pct=0 1641999600000000000",
+		"weather,location=house,observed_at=2022-01-12T12:00:00Z temperature_degc=2,relative_humidity_pct=0 1642003200000000000",
+	}, "\n")+"\n", body)
+}
+
+func TestIndexMixed(t *testing.T) {
+	var body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		body += string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	endpoint := strings.Replace(server.URL, "http://", "influx://", 1) + "/homestead?gzip=false"
+
+	idx, err := influxdb.Open(index.Config{Endpoint: endpoint, Mode: index.ModeRevisions})
+	require.NoError(t, err)
+
+	// locations are written ahead of the readings that refer to them
+	err = idx.Index(context.Background(),
+		&datasets.Location{Name: "home", UpdatedAt: observedAt, Latitude: 39.0473},
+		&datasets.Weather{Timestamp: timestamp, ObservedAt: observedAt, Location: "home", Temperature: 1},
+	)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	require.Len(t, lines, 2)
+
+	// the series of each point is described by everything before the first space
+	require.Equal(t, "locations,name=home", strings.SplitN(lines[0], " ", 2)[0])
+	require.Equal(t, "weather,location=home,observed_at=2022-01-12T12:00:00Z", strings.SplitN(lines[1], " ", 2)[0])
+}
+
+func TestIndexTimeout(t *testing.T) {
+	attempts := int32(0)
+	stalled := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt stalls until it is abandoned
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-stalled
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	endpoint := strings.Replace(server.URL, "http://", "influx://", 1) + "/homestead?timeout=50ms"
+
+	idx, err := influxdb.Open(index.Config{Endpoint: endpoint})
+	require.NoError(t, err)
+
+	err = idx.Index(context.Background(), &reading{Timestamp: timestamp, Temperature: 1})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestIndexError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":"unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	endpoint := strings.Replace(server.URL, "http://", "influx://", 1) + "/homestead?gzip=false"
+
+	idx, err := influxdb.Open(index.Config{Endpoint: endpoint})
+	require.NoError(t, err)
+
+	err = idx.Index(context.Background(), &reading{Timestamp: timestamp, Temperature: 1})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+}
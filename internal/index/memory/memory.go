@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+func init() {
+	index.Register(func(cfg index.Config) (index.Index, error) {
+		return Open(cfg)
+	}, "memory")
+}
+
+// Open returns an empty in-memory Index. Documents are replaced using the same keys as every other backend, making
+// it useful for tests and dry runs.
+func Open(cfg index.Config) (*Index, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = index.ModeLatest
+	}
+
+	return &Index{
+		cfg:       cfg,
+		positions: make(map[string]int),
+	}, nil
+}
+
+// Index holds documents in memory.
+type Index struct {
+	mu        sync.Mutex
+	cfg       index.Config
+	docs      []interface{}
+	positions map[string]int
+}
+
+func (idx *Index) Index(ctx context.Context, docs ...interface{}) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, doc := range docs {
+		identity, err := idx.cfg.Identity(doc)
+		if err != nil {
+			return err
+		}
+
+		if identity == "" {
+			idx.docs = append(idx.docs, doc)
+			continue
+		}
+
+		identity = index.Table(doc) + "\x00" + identity
+
+		if i, ok := idx.positions[identity]; ok {
+			idx.docs[i] = doc
+			continue
+		}
+
+		idx.positions[identity] = len(idx.docs)
+		idx.docs = append(idx.docs, doc)
+	}
+
+	zaputil.Extract(ctx).Info("recorded documents", zap.Int("docs", len(docs)))
+	return nil
+}
+
+// Documents returns every document that has been recorded, in the order they were first written.
+func (idx *Index) Documents() []interface{} {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	return append([]interface{}{}, idx.docs...)
+}
+
+// Find returns the documents with the same type as the provided template. When from or to are non-zero, only time
+// series documents within the [from, to) range are returned. Results are sorted by time.
+func (idx *Index) Find(template interface{}, from, to time.Time) []interface{} {
+	target := reflect.Indirect(reflect.ValueOf(template)).Type()
+	found := make([]interface{}, 0)
+
+	for _, doc := range idx.Documents() {
+		if reflect.Indirect(reflect.ValueOf(doc)).Type() != target {
+			continue
+		}
+
+		if !from.IsZero() || !to.IsZero() {
+			t, ok := index.Time(doc)
+
+			switch {
+			case !ok:
+				continue
+			case !from.IsZero() && t.Before(from):
+				continue
+			case !to.IsZero() && !t.Before(to):
+				continue
+			}
+		}
+
+		found = append(found, doc)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		ti, _ := index.Time(found[i])
+		tj, _ := index.Time(found[j])
+		return ti.Before(tj)
+	})
+
+	return found
+}
+
+// Summary describes the documents recorded for a single table.
+type Summary struct {
+	Table string
+	Count int
+	From  time.Time
+	To    time.Time
+}
+
+// Summarize returns a Summary for each table that has been written to, sorted by table name.
+func (idx *Index) Summarize() []Summary {
+	byTable := make(map[string]*Summary)
+
+	for _, doc := range idx.Documents() {
+		table := index.Table(doc)
+
+		summary, ok := byTable[table]
+		if !ok {
+			summary = &Summary{Table: table}
+			byTable[table] = summary
+		}
+
+		summary.Count++
+
+		if t, ok := index.Time(doc); ok {
+			if summary.From.IsZero() || t.Before(summary.From) {
+				summary.From = t
+			}
+
+			if t.After(summary.To) {
+				summary.To = t
+			}
+		}
+	}
+
+	summaries := make([]Summary, 0, len(byTable))
+	for _, summary := range byTable {
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Table < summaries[j].Table })
+
+	return summaries
+}
+
+func (idx *Index) Close() error {
+	return nil
+}
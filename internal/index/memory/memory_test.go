@@ -0,0 +1,70 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/memory"
+)
+
+type reading struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ObservedAt  time.Time `json:"observed_at"`
+	Temperature float64   `json:"temperature_degc"`
+}
+
+func (r reading) TableName() string    { return "weather" }
+func (r reading) NaturalKey() []string { return []string{"timestamp"} }
+func (r reading) RevisionKey() string  { return "observed_at" }
+func (r reading) TimeKey() string      { return "timestamp" }
+
+type note struct {
+	Text string `json:"text"`
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	for _, mode := range []string{index.ModeLatest, index.ModeRevisions} {
+		t.Run(mode, func(t *testing.T) {
+			idx, err := memory.Open(index.Config{Mode: mode})
+			require.NoError(t, err)
+
+			err = idx.Index(ctx,
+				&reading{Timestamp: start.Add(time.Hour), ObservedAt: start, Temperature: 2},
+				&reading{Timestamp: start, ObservedAt: start, Temperature: 1},
+				&note{Text: "hello"},
+			)
+			require.NoError(t, err)
+
+			err = idx.Index(ctx, &reading{Timestamp: start, ObservedAt: start.Add(time.Hour), Temperature: 3})
+			require.NoError(t, err)
+
+			readings := idx.Find(reading{}, start, start.Add(time.Hour))
+
+			switch mode {
+			case index.ModeLatest:
+				require.Len(t, idx.Documents(), 3)
+				require.Len(t, readings, 1)
+				require.Equal(t, 3.0, readings[0].(*reading).Temperature)
+			case index.ModeRevisions:
+				require.Len(t, idx.Documents(), 4)
+				require.Len(t, readings, 2)
+			}
+
+			require.Len(t, idx.Find(note{}, time.Time{}, time.Time{}), 1)
+
+			summaries := idx.Summarize()
+			require.Len(t, summaries, 2)
+			require.Equal(t, "note", summaries[0].Table)
+			require.Equal(t, "weather", summaries[1].Table)
+			require.Equal(t, start, summaries[1].From)
+			require.Equal(t, start.Add(time.Hour), summaries[1].To)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/index"
+)
+
+// Query finds documents matching the query. Every column of a document is returned regardless of query.Fields.
+func (idx *Index) Query(ctx context.Context, query index.Query, dest interface{}) error {
+	template, err := index.Template(dest)
+	if err != nil {
+		return err
+	}
+
+	err = query.Validate(template)
+	if err != nil {
+		return err
+	}
+
+	docs := idx.Find(template, query.From, query.To)
+
+	if query.Location != "" {
+		filtered := make([]interface{}, 0, len(docs))
+		locationKey := template.(index.Located).LocationKey()
+
+		for _, doc := range docs {
+			cols, err := index.Columns(doc)
+			if err != nil {
+				return err
+			}
+
+			for _, col := range cols {
+				if col.Name == locationKey && col.Value.IsValid() && col.Value.String() == query.Location {
+					filtered = append(filtered, doc)
+				}
+			}
+		}
+
+		docs = filtered
+	}
+
+	if document, ok := template.(index.Document); ok && query.LatestOnly {
+		docs, err = latest(docs, document.RevisionKey())
+		if err != nil {
+			return err
+		}
+	}
+
+	results := reflect.ValueOf(dest).Elem()
+	pointers := results.Type().Elem().Kind() == reflect.Ptr
+
+	for _, doc := range docs {
+		value := reflect.ValueOf(doc)
+
+		switch {
+		case pointers && value.Kind() != reflect.Ptr:
+			ptr := reflect.New(value.Type())
+			ptr.Elem().Set(value)
+			value = ptr
+		case !pointers && value.Kind() == reflect.Ptr:
+			value = value.Elem()
+		}
+
+		results.Set(reflect.Append(results, value))
+	}
+
+	return nil
+}
+
+func revision(doc interface{}, column string) time.Time {
+	cols, _ := index.Columns(doc)
+	for _, col := range cols {
+		if col.Name == column && col.Value.IsValid() {
+			t, _ := col.Value.Interface().(time.Time)
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// latest keeps only the most recent revision of each document, preserving the order of the documents.
+func latest(docs []interface{}, revisionKey string) ([]interface{}, error) {
+	keys := index.Config{Mode: index.ModeLatest}
+	positions := make(map[string]int)
+	results := make([]interface{}, 0, len(docs))
+
+	for _, doc := range docs {
+		identity, err := keys.Identity(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := positions[identity]
+		switch {
+		case !ok:
+			positions[identity] = len(results)
+			results = append(results, doc)
+		case !revision(doc, revisionKey).Before(revision(results[i], revisionKey)):
+			results[i] = doc
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,53 @@
+package orm
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Columns maps the json names used to describe a document to the names of the columns in the database. Names that
+// don't correspond to a json tag are assumed to already be column names.
+type Columns map[string]string
+
+// ColumnsOf parses the schema of the provided document to determine the database name of each column.
+func ColumnsOf(db *gorm.DB, doc interface{}) (Columns, error) {
+	stmt := &gorm.Statement{DB: db}
+
+	err := stmt.Parse(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(Columns, len(stmt.Schema.Fields))
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			columns[name] = field.DBName
+		}
+	}
+
+	return columns, nil
+}
+
+// Name returns the database column for the provided name.
+func (c Columns) Name(name string) string {
+	if column, ok := c[name]; ok {
+		return column
+	}
+
+	return name
+}
+
+// Names returns the database column for each of the provided names.
+func (c Columns) Names(names []string) []string {
+	columns := make([]string, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, c.Name(name))
+	}
+
+	return columns
+}
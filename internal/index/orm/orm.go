@@ -0,0 +1,370 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/myago/zaputil"
+)
+
+// Dialect captures the database specific behavior needed by an Index.
+type Dialect struct {
+	Dialector gorm.Dialector
+	// RowID is the name of the system column that uniquely identifies a row. It's used to break ties when removing
+	// duplicate rows.
+	RowID string
+	// MaxVariables is the largest number of variables that can be bound to a single statement. Batches are made
+	// smaller when writing wide documents would exceed it.
+	MaxVariables int
+	// Migrate performs any additional, database specific migrations once a table has been created.
+	Migrate func(db *gorm.DB, table string, columns Columns, doc interface{}) error
+}
+
+func Open(dialect Dialect, cfg index.Config) (*Index, error) {
+	gormConfig := &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	}
+
+	db, err := gorm.Open(dialect.Dialector, gormConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Mode == "" {
+		cfg.Mode = index.ModeLatest
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+
+	return &Index{dialect: dialect, cfg: cfg, db: db, keys: make(map[reflect.Type][]string)}, nil
+}
+
+// Index writes documents to a relational database using gorm.
+type Index struct {
+	dialect Dialect
+	cfg     index.Config
+	db      *gorm.DB
+
+	mu sync.Mutex
+	// keys contains the key columns of each document type that has been migrated
+	keys map[reflect.Type][]string
+}
+
+// migrate ensures the table for the provided document exists along with any supporting indexes, returning the
+// columns used to identify the document. Each document type is only migrated once.
+func (idx *Index) migrate(doc interface{}) ([]string, error) {
+	docType := reflect.TypeOf(doc)
+	if docType.Kind() == reflect.Ptr {
+		docType = docType.Elem()
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if key, ok := idx.keys[docType]; ok {
+		return key, nil
+	}
+
+	err := idx.db.AutoMigrate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := ColumnsOf(idx.db, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := idx.cfg.Key(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	key = columns.Names(key)
+	stmt := &gorm.Statement{DB: idx.db}
+	err = stmt.Parse(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	table := stmt.Schema.Table
+
+	if defaulted, ok := doc.(index.Defaulted); ok {
+		err = idx.backfill(table, key, columns, defaulted.Defaults())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(key) > 0 {
+		err = idx.uniqueKey(table, key, columns, doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if idx.dialect.Migrate != nil {
+		err = idx.dialect.Migrate(idx.db, table, columns, doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idx.keys[docType] = key
+	return key, nil
+}
+
+// backfill assigns default values to rows that were written before a column existed. When the column is part of the
+// key, rows that would then collide with a row already carrying the default are removed in favor of the existing row.
+func (idx *Index) backfill(table string, key []string, columns Columns, defaults map[string]interface{}) error {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return idx.db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range names {
+			value := defaults[name]
+			column := columns.Name(name)
+			zero := reflect.Zero(reflect.TypeOf(value)).Interface()
+
+			keyed := false
+			matches := make([]string, 0, len(key))
+			for _, k := range key {
+				if k == column {
+					keyed = true
+					continue
+				}
+
+				matches = append(matches, fmt.Sprintf("a.%q = b.%q", k, k))
+			}
+
+			if keyed {
+				matches = append(matches, fmt.Sprintf("b.%q = ?", column))
+
+				err := tx.Exec(fmt.Sprintf(
+					"DELETE FROM %q AS a WHERE (a.%q IS NULL OR a.%q = ?) AND EXISTS (SELECT 1 FROM %q AS b WHERE %s)",
+					table, column, column, table, strings.Join(matches, " AND "),
+				), zero, value).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			err := tx.Exec(fmt.Sprintf(
+				"UPDATE %q SET %q = ? WHERE %q IS NULL OR %q = ?",
+				table, column, column, column,
+			), value, zero).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// uniqueKey ensures that the table carries a unique index over the documents key. Any duplicate rows that would
+// violate the unique index are removed, keeping the most recent observation. The index is named after its columns so
+// that changes to the key (or the index mode) replace the previous index instead of being silently ignored.
+func (idx *Index) uniqueKey(table string, key []string, columns Columns, doc interface{}) error {
+	name := keyName(table, key)
+
+	// indexes created before they were named after their columns
+	stale := []string{
+		fmt.Sprintf("%s_%s_key", table, index.ModeLatest),
+		fmt.Sprintf("%s_%s_key", table, index.ModeRevisions),
+	}
+
+	for _, mode := range []string{index.ModeLatest, index.ModeRevisions} {
+		other, err := index.Config{Mode: mode}.Key(doc)
+		if err != nil {
+			return err
+		}
+
+		stale = append(stale, keyName(table, columns.Names(other)))
+	}
+
+	for _, staleName := range stale {
+		if staleName == name {
+			continue
+		}
+
+		err := idx.db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %q", staleName)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if idx.db.Migrator().HasIndex(doc, name) {
+		return nil
+	}
+
+	rowID := idx.dialect.RowID
+	quoted := make([]string, 0, len(key))
+	matches := make([]string, 0, len(key))
+	for _, column := range key {
+		quoted = append(quoted, fmt.Sprintf("%q", column))
+		matches = append(matches, fmt.Sprintf("a.%q = b.%q", column, column))
+	}
+
+	tiebreak := fmt.Sprintf("a.%s < b.%s", rowID, rowID)
+	if document, ok := doc.(index.Document); ok && idx.cfg.Mode == index.ModeLatest && document.RevisionKey() != "" {
+		revision := columns.Name(document.RevisionKey())
+		tiebreak = fmt.Sprintf("(a.%q < b.%q OR (a.%q = b.%q AND %s))", revision, revision, revision, revision, tiebreak)
+	}
+
+	return idx.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(fmt.Sprintf(
+			"DELETE FROM %q AS a WHERE EXISTS (SELECT 1 FROM %q AS b WHERE %s AND %s)",
+			table, table, strings.Join(matches, " AND "), tiebreak,
+		)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(fmt.Sprintf(
+			"CREATE UNIQUE INDEX IF NOT EXISTS %q ON %q (%s)",
+			name, table, strings.Join(quoted, ", "),
+		)).Error
+	})
+}
+
+// keyName returns the name of the unique index covering the provided key columns.
+func keyName(table string, key []string) string {
+	return fmt.Sprintf("%s_%s_key", table, strings.Join(key, "_"))
+}
+
+// partition groups consecutive documents of the same type into typed slices so that they can be written in batches.
+func partition(docs []interface{}) []interface{} {
+	partitions := make([]interface{}, 0, 1)
+	current := reflect.Value{}
+
+	for _, doc := range docs {
+		value := reflect.ValueOf(doc)
+
+		if !current.IsValid() || current.Type().Elem() != value.Type() {
+			if current.IsValid() {
+				partitions = append(partitions, current.Interface())
+			}
+
+			current = reflect.MakeSlice(reflect.SliceOf(value.Type()), 0, len(docs))
+		}
+
+		current = reflect.Append(current, value)
+	}
+
+	if current.IsValid() {
+		partitions = append(partitions, current.Interface())
+	}
+
+	return partitions
+}
+
+// batchSize returns the number of documents written by each insert, limiting the configured batch size so that a
+// single statement never binds more variables than the database supports.
+func (idx *Index) batchSize(doc interface{}) (int, error) {
+	size := idx.cfg.BatchSize
+	if idx.dialect.MaxVariables <= 0 {
+		return size, nil
+	}
+
+	stmt := &gorm.Statement{DB: idx.db}
+
+	err := stmt.Parse(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	if columns := len(stmt.Schema.DBNames); columns > 0 && idx.dialect.MaxVariables/columns < size {
+		size = idx.dialect.MaxVariables / columns
+	}
+
+	if size < 1 {
+		size = 1
+	}
+
+	return size, nil
+}
+
+func (idx *Index) Index(ctx context.Context, docs ...interface{}) (err error) {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	batches := partition(docs)
+	clauses := make([][]clause.Expression, len(batches))
+	sizes := make([]int, len(batches))
+
+	for i, batch := range batches {
+		doc := reflect.ValueOf(batch).Index(0).Interface()
+
+		key, err := idx.migrate(doc)
+		if err != nil {
+			return err
+		}
+
+		sizes[i], err = idx.batchSize(doc)
+		if err != nil {
+			return err
+		}
+
+		if len(key) > 0 {
+			columns := make([]clause.Column, 0, len(key))
+			for _, column := range key {
+				columns = append(columns, clause.Column{Name: column})
+			}
+
+			clauses[i] = append(clauses[i], clause.OnConflict{
+				Columns:   columns,
+				UpdateAll: true,
+			})
+		}
+	}
+
+	written := int64(0)
+
+	// all batches are written within a single transaction to ensure that a run is applied entirely or not at all
+	err = idx.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, batch := range batches {
+			result := tx.Clauses(clauses[i]...).CreateInBatches(batch, sizes[i])
+			if result.Error != nil {
+				return result.Error
+			}
+
+			written += result.RowsAffected
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	zaputil.Extract(ctx).Info("wrote rows", zap.Int64("rows", written))
+	return nil
+}
+
+func (idx *Index) Close() error {
+	db, err := idx.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.Close()
+}
@@ -0,0 +1,75 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjpitz/homestead/internal/index"
+)
+
+func (idx *Index) Query(ctx context.Context, query index.Query, dest interface{}) error {
+	template, err := index.Template(dest)
+	if err != nil {
+		return err
+	}
+
+	err = query.Validate(template)
+	if err != nil {
+		return err
+	}
+
+	// queries never modify the database, so a dataset that has yet to be written simply has no results
+	if !idx.db.WithContext(ctx).Migrator().HasTable(template) {
+		return nil
+	}
+
+	columns, err := ColumnsOf(idx.db, template)
+	if err != nil {
+		return err
+	}
+
+	timeKey := columns.Name(template.(index.TimeSeries).TimeKey())
+	table := index.Table(template)
+
+	tx := idx.db.WithContext(ctx).Table(fmt.Sprintf("%q AS a", table))
+
+	if len(query.Fields) > 0 {
+		selected := []string{fmt.Sprintf("a.%q", timeKey)}
+		for _, field := range columns.Names(query.Fields) {
+			if field != timeKey {
+				selected = append(selected, fmt.Sprintf("a.%q", field))
+			}
+		}
+
+		tx = tx.Select(strings.Join(selected, ", "))
+	}
+
+	if !query.From.IsZero() {
+		tx = tx.Where(fmt.Sprintf("a.%q >= ?", timeKey), query.From)
+	}
+
+	if !query.To.IsZero() {
+		tx = tx.Where(fmt.Sprintf("a.%q < ?", timeKey), query.To)
+	}
+
+	if query.Location != "" {
+		tx = tx.Where(fmt.Sprintf("a.%q = ?", columns.Name(template.(index.Located).LocationKey())), query.Location)
+	}
+
+	if document, ok := template.(index.Document); ok && query.LatestOnly && document.RevisionKey() != "" {
+		revision := columns.Name(document.RevisionKey())
+
+		matches := make([]string, 0)
+		for _, column := range columns.Names(document.NaturalKey()) {
+			matches = append(matches, fmt.Sprintf("b.%q = a.%q", column, column))
+		}
+
+		tx = tx.Where(fmt.Sprintf(
+			"a.%q = (SELECT MAX(b.%q) FROM %q AS b WHERE %s)",
+			revision, revision, table, strings.Join(matches, " AND "),
+		))
+	}
+
+	return tx.Order(fmt.Sprintf("a.%q", timeKey)).Find(dest).Error
+}
@@ -1,60 +1,24 @@
 package postgres
 
 import (
-	"sync"
-
 	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-)
-
-func Open(dsn string) (*Index, error) {
-	cfg := &gorm.Config{
-		SkipDefaultTransaction: true,
-		DisableAutomaticPing:   true,
-	}
-
-	db, err := gorm.Open(postgres.Open(dsn), cfg)
-	if err != nil {
-		return nil, err
-	}
 
-	return &Index{db: db}, nil
-}
-
-type Index struct {
-	once sync.Once
-	db   *gorm.DB
-}
-
-func (index *Index) Index(docs ...interface{}) (err error) {
-	if len(docs) == 0 {
-		return nil
-	}
-
-	index.once.Do(func() {
-		err = index.db.AutoMigrate(docs[0])
-	})
-
-	if err != nil {
-		return err
-	}
-
-	return index.db.Transaction(func(tx *gorm.DB) error {
-		for _, doc := range docs {
-			if err := tx.Create(doc).Error; err != nil {
-				return err
-			}
-		}
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/orm"
+)
 
-		return nil
-	})
+func init() {
+	index.Register(func(cfg index.Config) (index.Index, error) {
+		return Open(cfg)
+	}, "postgres", "postgresql")
 }
 
-func (index *Index) Close() error {
-	db, err := index.db.DB()
-	if err != nil {
-		return err
-	}
-
-	return db.Close()
+func Open(cfg index.Config) (*orm.Index, error) {
+	return orm.Open(orm.Dialect{
+		Dialector: postgres.Open(cfg.Endpoint),
+		RowID:     "ctid",
+		// the wire protocol uses a 16 bit integer for the number of parameters
+		MaxVariables: 65535,
+		Migrate:      migrate(cfg),
+	}, cfg)
 }
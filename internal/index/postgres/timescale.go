@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/orm"
+)
+
+type statement struct {
+	sql  string
+	args []interface{}
+}
+
+func interval(d time.Duration) string {
+	return fmt.Sprintf("%d seconds", int64(d/time.Second))
+}
+
+func migrate(cfg index.Config) func(db *gorm.DB, table string, columns orm.Columns, doc interface{}) error {
+	return func(db *gorm.DB, table string, columns orm.Columns, doc interface{}) error {
+		if series, ok := doc.(index.TimeSeries); ok && cfg.Timescale.Enabled {
+			return hypertable(db, cfg.Timescale, table, columns.Name(series.TimeKey()))
+		}
+
+		return nil
+	}
+}
+
+// hypertable converts the provided table into a TimescaleDB hypertable partitioned by the provided column. Compression
+// and retention policies are only replaced when their interval changes so that repeated runs leave existing policies
+// (and compressed chunks) untouched.
+func hypertable(db *gorm.DB, cfg index.TimescaleConfig, table, column string) error {
+	if cfg.ChunkInterval <= 0 {
+		cfg.ChunkInterval = 24 * time.Hour
+	}
+
+	statements := []statement{
+		{"CREATE EXTENSION IF NOT EXISTS timescaledb", nil},
+		{
+			"SELECT create_hypertable(?::regclass, ?::name, chunk_time_interval => ?::interval, if_not_exists => TRUE, migrate_data => TRUE)",
+			[]interface{}{table, column, interval(cfg.ChunkInterval)},
+		},
+		{"SELECT set_chunk_time_interval(?::regclass, ?::interval)", []interface{}{table, interval(cfg.ChunkInterval)}},
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range statements {
+			err := tx.Exec(stmt.sql, stmt.args...).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if cfg.CompressAfter > 0 {
+			// compression can't be reconfigured once chunks have been compressed, so it's only enabled once
+			enabled := false
+
+			err := tx.Raw(
+				"SELECT compression_enabled FROM timescaledb_information.hypertables WHERE hypertable_schema = current_schema() AND hypertable_name = ?",
+				table,
+			).Scan(&enabled).Error
+			if err != nil {
+				return err
+			}
+
+			if !enabled {
+				err = tx.Exec(fmt.Sprintf("ALTER TABLE %q SET (timescaledb.compress, timescaledb.compress_orderby = '%q DESC')", table, column)).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err := policy(tx, table, "compression", "compress_after", cfg.CompressAfter)
+		if err != nil {
+			return err
+		}
+
+		return policy(tx, table, "retention", "drop_after", cfg.RetainFor)
+	})
+}
+
+// policy ensures the table has a compression or retention policy acting on chunks older than the provided duration,
+// removing it when the duration is zero. An existing policy is only replaced when its interval differs.
+func policy(tx *gorm.DB, table, kind, key string, after time.Duration) error {
+	current := make([]bool, 0)
+
+	err := tx.Raw(
+		"SELECT (config->>?)::interval = ?::interval FROM timescaledb_information.jobs WHERE hypertable_schema = current_schema() AND hypertable_name = ? AND proc_name = ?",
+		key, interval(after), table, "policy_"+kind,
+	).Scan(&current).Error
+	if err != nil {
+		return err
+	}
+
+	if len(current) > 0 && after > 0 && current[0] {
+		return nil
+	}
+
+	if len(current) > 0 {
+		err = tx.Exec(fmt.Sprintf("SELECT remove_%s_policy(?::regclass, if_exists => TRUE)", kind), table).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if after <= 0 {
+		return nil
+	}
+
+	return tx.Exec(fmt.Sprintf("SELECT add_%s_policy(?::regclass, ?::interval)", kind), table, interval(after)).Error
+}
@@ -0,0 +1,44 @@
+package prometheus_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/prometheus"
+)
+
+func TestIndexTimeout(t *testing.T) {
+	attempts := int32(0)
+	stalled := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+
+		// the first attempt stalls until it is abandoned
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-stalled
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	endpoint := strings.Replace(server.URL, "http://", "prom://", 1) + "/api/v1/push?timeout=50ms&future=keep"
+
+	idx, err := prometheus.Open(index.Config{Endpoint: endpoint})
+	require.NoError(t, err)
+
+	err = idx.Index(context.Background(), &reading{Timestamp: time.Now(), Location: "house", Temperature: 1})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,70 @@
+package prometheus
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Label is a name/value pair that identifies a series.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a series at a point in time, expressed in milliseconds since the unix epoch.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// TimeSeries is a set of samples that share the same labels.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendLabel(b []byte, label Label) []byte {
+	msg := protowire.AppendTag(nil, 1, protowire.BytesType)
+	msg = protowire.AppendString(msg, label.Name)
+	msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+	msg = protowire.AppendString(msg, label.Value)
+
+	return appendMessage(b, 1, msg)
+}
+
+func appendSample(b []byte, sample Sample) []byte {
+	msg := protowire.AppendTag(nil, 1, protowire.Fixed64Type)
+	msg = protowire.AppendFixed64(msg, math.Float64bits(sample.Value))
+	msg = protowire.AppendTag(msg, 2, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(sample.Timestamp))
+
+	return appendMessage(b, 2, msg)
+}
+
+// MarshalWriteRequest encodes the provided series as a prometheus.WriteRequest protobuf message, as defined by the
+// remote write specification. The result still needs to be snappy compressed before being sent.
+func MarshalWriteRequest(series []TimeSeries) []byte {
+	var b []byte
+
+	for _, ts := range series {
+		var msg []byte
+
+		for _, label := range ts.Labels {
+			msg = appendLabel(msg, label)
+		}
+
+		for _, sample := range ts.Samples {
+			msg = appendSample(msg, sample)
+		}
+
+		b = appendMessage(b, 1, msg)
+	}
+
+	return b
+}
@@ -0,0 +1,178 @@
+package prometheus
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mjpitz/homestead/internal/index"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+const (
+	// FutureDrop discards samples that are further in the future than the configured grace period. Most remote write
+	// receivers reject these samples.
+	FutureDrop = "drop"
+	// FutureKeep sends samples regardless of their timestamp. This requires a receiver that accepts future samples,
+	// such as VictoriaMetrics.
+	FutureKeep = "keep"
+)
+
+// Sanitize replaces any characters that are not valid within a metric or label name with an underscore.
+func Sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+
+		return '_'
+	}, name)
+}
+
+// Converter turns documents into remote write series. Each numeric column becomes its own metric named using the
+// namespace, table, and json tag of the column (e.g. homestead_weather_temperature_degc) while the natural key of an
+// index.Document is attached to every metric as labels. Any other string or time columns are dropped.
+type Converter struct {
+	Namespace string
+	// Labels lists additional columns that should be written as labels instead of being dropped.
+	Labels []string
+	// Mode is the index mode. When using index.ModeRevisions, the revision column of each document is also written as
+	// a label so that every revision is retained.
+	Mode string
+	// Future determines how samples with a timestamp after Now+Grace are handled.
+	Future string
+	Grace  time.Duration
+	Now    time.Time
+}
+
+// labelsOf returns the columns of the document that are written as labels.
+func (c Converter) labelsOf(doc interface{}) map[string]bool {
+	labels := make(map[string]bool, len(c.Labels))
+	for _, label := range c.Labels {
+		labels[label] = true
+	}
+
+	if document, ok := doc.(index.Document); ok {
+		for _, column := range document.NaturalKey() {
+			labels[column] = true
+		}
+
+		if revision := document.RevisionKey(); revision != "" && c.Mode == index.ModeRevisions {
+			labels[revision] = true
+		}
+	}
+
+	return labels
+}
+
+func key(labels []Label) string {
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, label.Name+"\xff"+label.Value)
+	}
+
+	return strings.Join(parts, "\xfe")
+}
+
+// Convert produces the series for the provided documents. Samples within each series are sorted by time.
+func (c Converter) Convert(docs ...interface{}) ([]TimeSeries, error) {
+	bySeries := make(map[string]*TimeSeries)
+	order := make([]string, 0)
+
+	for _, doc := range docs {
+		cols, err := index.Columns(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		timeKey := ""
+		if series, ok := doc.(index.TimeSeries); ok {
+			timeKey = series.TimeKey()
+		}
+
+		isLabel := c.labelsOf(doc)
+		timestamp := c.Now
+		labels := make([]Label, 0)
+		values := make(map[string]float64)
+		names := make([]string, 0)
+
+		for _, col := range cols {
+			if !col.Value.IsValid() {
+				continue
+			}
+
+			if col.Type == timeType {
+				t := col.Value.Interface().(time.Time)
+
+				switch {
+				case col.Name == timeKey:
+					timestamp = t
+				case isLabel[col.Name] && !t.IsZero():
+					labels = append(labels, Label{Sanitize(col.Name), t.UTC().Format(time.RFC3339)})
+				}
+
+				continue
+			}
+
+			var value float64
+
+			switch col.Type.Kind() {
+			case reflect.String:
+				if v := col.Value.String(); v != "" && isLabel[col.Name] {
+					labels = append(labels, Label{Sanitize(col.Name), v})
+				}
+
+				continue
+			case reflect.Float32, reflect.Float64:
+				value = col.Value.Float()
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				value = float64(col.Value.Int())
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				value = float64(col.Value.Uint())
+			case reflect.Bool:
+				if col.Value.Bool() {
+					value = 1
+				}
+			default:
+				continue
+			}
+
+			names = append(names, col.Name)
+			values[col.Name] = value
+		}
+
+		if c.Future != FutureKeep && timestamp.After(c.Now.Add(c.Grace)) {
+			continue
+		}
+
+		prefix := Sanitize(c.Namespace + "_" + index.Table(doc) + "_")
+
+		for _, name := range names {
+			seriesLabels := append([]Label{{"__name__", prefix + Sanitize(name)}}, labels...)
+			sort.Slice(seriesLabels, func(i, j int) bool { return seriesLabels[i].Name < seriesLabels[j].Name })
+
+			k := key(seriesLabels)
+			if _, ok := bySeries[k]; !ok {
+				bySeries[k] = &TimeSeries{Labels: seriesLabels}
+				order = append(order, k)
+			}
+
+			bySeries[k].Samples = append(bySeries[k].Samples, Sample{
+				Value:     values[name],
+				Timestamp: timestamp.UnixMilli(),
+			})
+		}
+	}
+
+	series := make([]TimeSeries, 0, len(order))
+	for _, k := range order {
+		ts := bySeries[k]
+		sort.Slice(ts.Samples, func(i, j int) bool { return ts.Samples[i].Timestamp < ts.Samples[j].Timestamp })
+		series = append(series, *ts)
+	}
+
+	return series, nil
+}
@@ -0,0 +1,109 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/prometheus"
+)
+
+type reading struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ObservedAt  time.Time `json:"observed_at"`
+	Location    string    `json:"location"`
+	Conditions  string    `json:"conditions"`
+	Temperature float64   `json:"temperature_degc"`
+}
+
+func (r reading) TableName() string    { return "weather" }
+func (r reading) NaturalKey() []string { return []string{"location", "timestamp"} }
+func (r reading) RevisionKey() string  { return "observed_at" }
+func (r reading) TimeKey() string      { return "timestamp" }
+
+func TestConvert(t *testing.T) {
+	now := time.Date(2022, 1, 12, 12, 0, 0, 0, time.UTC)
+
+	converter := prometheus.Converter{
+		Namespace: "homestead",
+		Future:    prometheus.FutureDrop,
+		Grace:     10 * time.Minute,
+		Now:       now,
+	}
+
+	series, err := converter.Convert(
+		&reading{Timestamp: now.Add(5 * time.Minute), ObservedAt: now, Location: "house", Conditions: "fog", Temperature: 2},
+		&reading{Timestamp: now.Add(-time.Hour), Location: "house", Temperature: 1},
+		&reading{Timestamp: now.Add(time.Hour), Location: "house", Temperature: 3},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, series, 1)
+	require.Equal(t, []prometheus.Label{
+		{Name: "__name__", Value: "homestead_weather_temperature_degc"},
+		{Name: "location", Value: "house"},
+	}, series[0].Labels)
+	require.Equal(t, []prometheus.Sample{
+		{Value: 1, Timestamp: now.Add(-time.Hour).UnixMilli()},
+		{Value: 2, Timestamp: now.Add(5 * time.Minute).UnixMilli()},
+	}, series[0].Samples)
+
+	converter.Future = prometheus.FutureKeep
+
+	series, err = converter.Convert(&reading{Timestamp: now.Add(time.Hour), Location: "house", Temperature: 3})
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Samples, 1)
+}
+
+func TestConvertMixed(t *testing.T) {
+	now := time.Date(2022, 1, 12, 12, 0, 0, 0, time.UTC)
+
+	converter := prometheus.Converter{
+		Namespace: "homestead",
+		Mode:      index.ModeRevisions,
+		Future:    prometheus.FutureKeep,
+		Now:       now,
+	}
+
+	// locations are written ahead of the readings that refer to them
+	series, err := converter.Convert(
+		&datasets.Location{Name: "home", UpdatedAt: now, Latitude: 39.0473},
+		&datasets.Weather{Timestamp: now, ObservedAt: now.Add(-time.Hour), Location: "home", Temperature: 1},
+		&datasets.Weather{Timestamp: now, ObservedAt: now, Location: "home", Temperature: 2},
+	)
+	require.NoError(t, err)
+
+	labels := func(metric string) [][]prometheus.Label {
+		matches := make([][]prometheus.Label, 0)
+		for _, ts := range series {
+			if ts.Labels[0].Value == metric {
+				matches = append(matches, ts.Labels)
+			}
+		}
+
+		return matches
+	}
+
+	require.Equal(t, [][]prometheus.Label{{
+		{Name: "__name__", Value: "homestead_locations_latitude"},
+		{Name: "name", Value: "home"},
+	}}, labels("homestead_locations_latitude"))
+
+	// each revision of the forecast is kept as its own series
+	require.Equal(t, [][]prometheus.Label{
+		{
+			{Name: "__name__", Value: "homestead_weather_temperature_degc"},
+			{Name: "location", Value: "home"},
+			{Name: "observed_at", Value: "2022-01-12T11:00:00Z"},
+		},
+		{
+			{Name: "__name__", Value: "homestead_weather_temperature_degc"},
+			{Name: "location", Value: "home"},
+			{Name: "observed_at", Value: "2022-01-12T12:00:00Z"},
+		},
+	}, labels("homestead_weather_temperature_degc"))
+}
@@ -0,0 +1,79 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Located is implemented by documents that are associated with a named location.
+type Located interface {
+	// LocationKey returns the column containing the name of the location the document describes.
+	LocationKey() string
+}
+
+// Query describes a request for time series documents.
+type Query struct {
+	// From and To restrict results to documents within the [From, To) range. Zero values are unbounded.
+	From time.Time
+	To   time.Time
+	// Fields lists the columns to return in addition to the time column. When empty, every column is returned.
+	// Backends may return more columns than requested.
+	Fields []string
+	// Location restricts results to documents associated with the named location.
+	Location string
+	// LatestOnly returns only the most recent revision of each document.
+	LatestOnly bool
+}
+
+// Validate ensures the query can be run against the provided document type.
+func (q Query) Validate(template interface{}) error {
+	if _, ok := template.(TimeSeries); !ok {
+		return fmt.Errorf("%s documents are not a time series", Table(template))
+	}
+
+	if _, ok := template.(Located); !ok && q.Location != "" {
+		return fmt.Errorf("%s documents are not associated with a location", Table(template))
+	}
+
+	cols, err := Columns(template)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		names[col.Name] = true
+	}
+
+	for _, field := range q.Fields {
+		if !names[field] {
+			return fmt.Errorf("unknown field for %s: %s", Table(template), field)
+		}
+	}
+
+	return nil
+}
+
+// Template returns a pointer to a zero value of the element type of dest, which must be a pointer to a slice.
+func Template(dest interface{}) (interface{}, error) {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("destination must be a pointer to a slice, got %T", dest)
+	}
+
+	elem := value.Elem().Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return reflect.New(elem).Interface(), nil
+}
+
+// Querier is implemented by indexes that can read documents back.
+type Querier interface {
+	// Query finds documents matching the query and appends them to dest, which must be a pointer to a slice of
+	// documents. Results are sorted by time.
+	Query(ctx context.Context, query Query, dest interface{}) error
+}
@@ -0,0 +1,75 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultScheme is used to select a backend when the endpoint does not contain a scheme, such as postgres key/value
+// connection strings.
+const DefaultScheme = "postgres"
+
+// Opener constructs an Index from the provided configuration.
+type Opener func(cfg Config) (Index, error)
+
+var (
+	mu      sync.RWMutex
+	openers = make(map[string]Opener)
+)
+
+// Register associates the opener with each of the provided URL schemes. Backends typically call this from an init
+// function so that importing the package is enough to make it available.
+func Register(opener Opener, schemes ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, scheme := range schemes {
+		if _, ok := openers[scheme]; ok {
+			panic(fmt.Sprintf("index backend already registered for scheme: %s", scheme))
+		}
+
+		openers[scheme] = opener
+	}
+}
+
+// Schemes returns the sorted list of schemes that have a registered backend.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	schemes := make([]string, 0, len(openers))
+	for scheme := range openers {
+		schemes = append(schemes, scheme)
+	}
+
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Scheme returns the URL scheme of the provided endpoint, or the DefaultScheme if none is present.
+func Scheme(endpoint string) string {
+	i := strings.Index(endpoint, "://")
+	if i < 0 {
+		return DefaultScheme
+	}
+
+	return strings.ToLower(endpoint[:i])
+}
+
+// Open constructs an Index using the backend registered for the scheme of the configured endpoint.
+func Open(cfg Config) (Index, error) {
+	scheme := Scheme(cfg.Endpoint)
+
+	mu.RLock()
+	opener, ok := openers[scheme]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no index backend registered for scheme %q (available: %s)",
+			scheme, strings.Join(Schemes(), ", "))
+	}
+
+	return opener(cfg)
+}
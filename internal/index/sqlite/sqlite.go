@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/driver/sqlite"
+
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/orm"
+)
+
+// DSN converts an endpoint of the form sqlite:///path/to/file.db into a data source name understood by the sqlite
+// driver. Unless otherwise specified, the database is opened in WAL mode so readers like Grafana do not block writes.
+func DSN(endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, "sqlite://") {
+		return "", fmt.Errorf("invalid sqlite endpoint: %s", endpoint)
+	}
+
+	path := strings.TrimPrefix(endpoint, "sqlite://")
+
+	query := url.Values{}
+	if i := strings.IndexByte(path, '?'); i > -1 {
+		var err error
+
+		query, err = url.ParseQuery(path[i+1:])
+		if err != nil {
+			return "", err
+		}
+
+		path = path[:i]
+	}
+
+	if path == "" {
+		return "", fmt.Errorf("missing sqlite database path: %s", endpoint)
+	}
+
+	if query.Get("_journal_mode") == "" {
+		query.Set("_journal_mode", "WAL")
+	}
+
+	if query.Get("_busy_timeout") == "" {
+		query.Set("_busy_timeout", "5000")
+	}
+
+	return "file:" + path + "?" + query.Encode(), nil
+}
+
+func init() {
+	index.Register(func(cfg index.Config) (index.Index, error) {
+		return Open(cfg)
+	}, "sqlite")
+}
+
+func Open(cfg index.Config) (*orm.Index, error) {
+	dsn, err := DSN(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return orm.Open(orm.Dialect{
+		Dialector: sqlite.Open(dsn),
+		RowID:     "rowid",
+		// SQLITE_MAX_VARIABLE_NUMBER defaults to 32766 as of 3.32.0
+		MaxVariables: 32766,
+	}, cfg)
+}
@@ -0,0 +1,218 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	driver "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/mjpitz/homestead/internal/datasets"
+	"github.com/mjpitz/homestead/internal/index"
+	"github.com/mjpitz/homestead/internal/index/sqlite"
+)
+
+func endpoint(t *testing.T) string {
+	return "sqlite://" + filepath.Join(t.TempDir(), "index.db")
+}
+
+type reading struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ObservedAt  time.Time `json:"observed_at"`
+	Temperature float64   `json:"temperature_degc"`
+}
+
+func (r reading) TableName() string    { return "readings" }
+func (r reading) NaturalKey() []string { return []string{"timestamp"} }
+func (r reading) RevisionKey() string  { return "observed_at" }
+func (r reading) TimeKey() string      { return "timestamp" }
+
+func query(t *testing.T, cfg index.Config, location string) []*datasets.Weather {
+	results := make([]*datasets.Weather, 0)
+	find(t, cfg, index.Query{Location: location}, &results)
+
+	return results
+}
+
+func readings(t *testing.T, cfg index.Config) []*reading {
+	results := make([]*reading, 0)
+	find(t, cfg, index.Query{}, &results)
+
+	return results
+}
+
+func find(t *testing.T, cfg index.Config, query index.Query, dest interface{}) {
+	idx, err := sqlite.Open(cfg)
+	require.NoError(t, err)
+	defer idx.Close()
+
+	require.NoError(t, idx.Query(context.Background(), query, dest))
+}
+
+func write(t *testing.T, cfg index.Config, docs ...interface{}) {
+	idx, err := sqlite.Open(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, idx.Index(context.Background(), docs...))
+	require.NoError(t, idx.Close())
+}
+
+func TestIndex(t *testing.T) {
+	start := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	t.Run("upsert", func(t *testing.T) {
+		cfg := index.Config{Endpoint: endpoint(t), Mode: index.ModeLatest}
+
+		write(t, cfg,
+			&reading{Timestamp: start, ObservedAt: start, Temperature: 1},
+			&reading{Timestamp: start.Add(time.Hour), ObservedAt: start, Temperature: 2},
+		)
+
+		// rewriting a timestamp replaces the existing row
+		write(t, cfg, &reading{Timestamp: start, ObservedAt: start.Add(time.Hour), Temperature: 3})
+
+		results := readings(t, cfg)
+		require.Len(t, results, 2)
+		require.Equal(t, 3.0, results[0].Temperature)
+		require.Equal(t, start.Add(time.Hour), results[0].ObservedAt.UTC())
+		require.Equal(t, 2.0, results[1].Temperature)
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		cfg := index.Config{Endpoint: endpoint(t), Mode: index.ModeRevisions}
+
+		write(t, cfg,
+			&reading{Timestamp: start, ObservedAt: start, Temperature: 1},
+			&reading{Timestamp: start, ObservedAt: start.Add(time.Hour), Temperature: 2},
+		)
+
+		// rewriting a revision replaces it rather than adding another
+		write(t, cfg, &reading{Timestamp: start, ObservedAt: start.Add(time.Hour), Temperature: 3})
+
+		results := readings(t, cfg)
+		require.Len(t, results, 2)
+		require.ElementsMatch(t, []float64{1, 3}, []float64{results[0].Temperature, results[1].Temperature})
+	})
+
+	t.Run("switching modes", func(t *testing.T) {
+		revisions := index.Config{Endpoint: endpoint(t), Mode: index.ModeRevisions}
+		latest := index.Config{Endpoint: revisions.Endpoint, Mode: index.ModeLatest}
+
+		write(t, revisions,
+			&reading{Timestamp: start, ObservedAt: start, Temperature: 1},
+			&reading{Timestamp: start, ObservedAt: start.Add(time.Hour), Temperature: 2},
+		)
+
+		// writing in latest mode keeps only the most recent revision of each timestamp
+		write(t, latest, &reading{Timestamp: start.Add(time.Hour), ObservedAt: start, Temperature: 3})
+
+		results := readings(t, latest)
+		require.Len(t, results, 2)
+		require.Equal(t, 2.0, results[0].Temperature)
+
+		// and switching back allows revisions to accumulate again
+		write(t, revisions, &reading{Timestamp: start, ObservedAt: start.Add(2 * time.Hour), Temperature: 4})
+		require.Len(t, readings(t, revisions), 3)
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		cfg := index.Config{Endpoint: endpoint(t), Mode: index.ModeLatest, BatchSize: 2}
+
+		docs := make([]interface{}, 0, 5)
+		for i := 0; i < cap(docs); i++ {
+			docs = append(docs, &reading{Timestamp: start.Add(time.Duration(i) * time.Hour), Temperature: float64(i)})
+		}
+
+		write(t, cfg, docs...)
+		write(t, cfg, docs...)
+
+		results := readings(t, cfg)
+		require.Len(t, results, len(docs))
+		require.Equal(t, float64(len(docs)-1), results[len(results)-1].Temperature)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+	cfg := index.Config{Endpoint: endpoint(t), Mode: index.ModeRevisions, BatchSize: 500}
+
+	t.Run("missing table", func(t *testing.T) {
+		require.Empty(t, query(t, cfg, "home"))
+	})
+
+	idx, err := sqlite.Open(cfg)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		err = idx.Index(ctx, &datasets.Weather{
+			Timestamp:   start,
+			ObservedAt:  start.Add(time.Duration(i) * time.Hour),
+			Location:    "home",
+			Temperature: float64(i),
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, idx.Close())
+
+	t.Run("leaves revisions intact", func(t *testing.T) {
+		// reading in latest mode must not collapse the revisions written above
+		require.Len(t, query(t, index.Config{Endpoint: cfg.Endpoint, Mode: index.ModeLatest}, "home"), 3)
+		require.Len(t, query(t, cfg, "home"), 3)
+	})
+}
+
+func TestIndexBatches(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+	cfg := index.Config{Endpoint: endpoint(t), Mode: index.ModeLatest, BatchSize: 500}
+
+	idx, err := sqlite.Open(cfg)
+	require.NoError(t, err)
+
+	// enough wide documents to span more than one batch, each of which must stay within sqlite's variable limit
+	docs := make([]interface{}, 0, 1200)
+	for i := 0; i < cap(docs); i++ {
+		docs = append(docs, &datasets.Weather{
+			Timestamp:   start.Add(time.Duration(i) * time.Hour),
+			ObservedAt:  start,
+			Location:    "home",
+			Temperature: float64(i),
+		})
+	}
+
+	require.NoError(t, idx.Index(ctx, docs...))
+	require.NoError(t, idx.Close())
+
+	results := query(t, cfg, "home")
+	require.Len(t, results, len(docs))
+	require.Equal(t, float64(len(docs)-1), results[len(results)-1].Temperature)
+}
+
+func TestIndexLocations(t *testing.T) {
+	start := time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC)
+	cfg := index.Config{Endpoint: endpoint(t), Mode: index.ModeRevisions}
+
+	// locations are referenced by name, so even revisions mode keeps a single row for each
+	write(t, cfg, &datasets.Location{Name: "home", UpdatedAt: start, GridID: "TOP"})
+	write(t, cfg, &datasets.Location{Name: "home", UpdatedAt: start.Add(time.Hour), GridID: "EAX"})
+
+	dsn, err := sqlite.DSN(cfg.Endpoint)
+	require.NoError(t, err)
+
+	db, err := gorm.Open(driver.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	locations := make([]*datasets.Location, 0)
+	require.NoError(t, db.Find(&locations).Error)
+	require.Len(t, locations, 1)
+	require.Equal(t, "EAX", locations[0].GridID)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+}
@@ -0,0 +1,142 @@
+// Package resample expands values that hold over an interval of time, such as those returned by the weather.gov
+// gridpoint endpoint, into samples at a fixed resolution.
+package resample
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type Strategy string
+
+const (
+	// StepHold repeats the value of an interval for every sample it covers.
+	StepHold Strategy = "step"
+	// Linear interpolates between the start of consecutive intervals, holding the final value until its interval
+	// ends. Intervals separated by a gap are not interpolated across.
+	Linear Strategy = "linear"
+	// Accumulate treats the value as a total over the interval (e.g. precipitation) and splits it across the samples
+	// it covers in proportion to their overlap, so that the sum of the samples equals the original total.
+	Accumulate Strategy = "accumulate"
+)
+
+// ParseStrategy returns the strategy with the provided name.
+func ParseStrategy(name string) (Strategy, error) {
+	switch strategy := Strategy(name); strategy {
+	case StepHold, Linear, Accumulate:
+		return strategy, nil
+	}
+
+	return "", fmt.Errorf("unknown resampling strategy: %q", name)
+}
+
+// Interval is a value that holds from Start for Duration. A zero Duration describes an instant.
+type Interval struct {
+	Start    time.Time
+	Duration time.Duration
+	Value    float64
+}
+
+func (i Interval) end() time.Time {
+	return i.Start.Add(i.Duration)
+}
+
+// Sample is the value of the series at Time.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Resample converts the intervals into samples aligned to the resolution, sorted by time. Each sample describes the
+// period [Time, Time + resolution). Intervals that are shorter than the resolution, including instants, produce a
+// sample for the period containing their start.
+func Resample(intervals []Interval, strategy Strategy, resolution time.Duration) ([]Sample, error) {
+	if resolution <= 0 {
+		return nil, fmt.Errorf("resolution must be positive, got %s", resolution)
+	}
+
+	sorted := make([]Interval, len(intervals))
+	copy(sorted, intervals)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	values := make(map[int64]float64)
+
+	for i, interval := range sorted {
+		buckets := covered(interval, resolution)
+
+		switch strategy {
+		case StepHold:
+			for _, bucket := range buckets {
+				values[bucket.UnixNano()] = interval.Value
+			}
+
+		case Linear:
+			// interpolate toward the next interval when it begins as this one ends
+			var next *Interval
+			if i+1 < len(sorted) && interval.Duration > 0 && sorted[i+1].Start.Equal(interval.end()) {
+				next = &sorted[i+1]
+			}
+
+			for _, bucket := range buckets {
+				value := interval.Value
+
+				if next != nil && bucket.After(interval.Start) {
+					progress := float64(bucket.Sub(interval.Start)) / float64(interval.Duration)
+					value += (next.Value - interval.Value) * progress
+				}
+
+				values[bucket.UnixNano()] = value
+			}
+
+		case Accumulate:
+			if interval.Duration <= 0 {
+				values[buckets[0].UnixNano()] += interval.Value
+				continue
+			}
+
+			for _, bucket := range buckets {
+				start, end := bucket, bucket.Add(resolution)
+				if start.Before(interval.Start) {
+					start = interval.Start
+				}
+
+				if end.After(interval.end()) {
+					end = interval.end()
+				}
+
+				values[bucket.UnixNano()] += interval.Value * float64(end.Sub(start)) / float64(interval.Duration)
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown resampling strategy: %q", strategy)
+		}
+	}
+
+	samples := make([]Sample, 0, len(values))
+	for nanos, value := range values {
+		samples = append(samples, Sample{Time: time.Unix(0, nanos).UTC(), Value: value})
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+
+	return samples, nil
+}
+
+// covered returns the start of every period that overlaps the interval. An interval always covers at least the period
+// containing its start.
+func covered(interval Interval, resolution time.Duration) []time.Time {
+	first := interval.Start.Truncate(resolution)
+	buckets := []time.Time{first}
+
+	for bucket := first.Add(resolution); bucket.Before(interval.end()); bucket = bucket.Add(resolution) {
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
+
+type Config struct {
+	Strategy   string        `json:"strategy"   usage:"how values are expanded between forecast intervals (step or linear)" default:"step"`
+	Resolution time.Duration `json:"resolution" usage:"the time between each document" default:"15m"`
+}
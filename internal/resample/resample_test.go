@@ -0,0 +1,100 @@
+package resample_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/resample"
+)
+
+var start = time.Date(2022, 1, 12, 15, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
+
+func values(samples []resample.Sample) map[time.Time]float64 {
+	result := make(map[time.Time]float64, len(samples))
+	for _, sample := range samples {
+		result[sample.Time] = sample.Value
+	}
+
+	return result
+}
+
+func TestStepHold(t *testing.T) {
+	samples, err := resample.Resample([]resample.Interval{
+		{Start: at(0), Duration: 30 * time.Minute, Value: 1},
+		// an instant must not prevent later intervals from being expanded
+		{Start: at(30), Value: 2},
+		{Start: at(45), Duration: 30 * time.Minute, Value: 3},
+	}, resample.StepHold, 15*time.Minute)
+	require.NoError(t, err)
+
+	require.Equal(t, map[time.Time]float64{
+		at(0):  1,
+		at(15): 1,
+		at(30): 2,
+		at(45): 3,
+		at(60): 3,
+	}, values(samples))
+}
+
+func TestLinear(t *testing.T) {
+	samples, err := resample.Resample([]resample.Interval{
+		{Start: at(0), Duration: time.Hour, Value: 0},
+		{Start: at(60), Duration: 30 * time.Minute, Value: 8},
+		// separated by a gap, so no interpolation occurs from the previous interval
+		{Start: at(120), Duration: 15 * time.Minute, Value: 100},
+	}, resample.Linear, 15*time.Minute)
+	require.NoError(t, err)
+
+	require.Equal(t, map[time.Time]float64{
+		at(0):   0,
+		at(15):  2,
+		at(30):  4,
+		at(45):  6,
+		at(60):  8,
+		at(75):  8,
+		at(120): 100,
+	}, values(samples))
+}
+
+func TestAccumulate(t *testing.T) {
+	samples, err := resample.Resample([]resample.Interval{
+		{Start: at(0), Duration: time.Hour, Value: 4},
+		{Start: at(60), Duration: 6 * time.Minute, Value: 1},
+		{Start: at(65), Value: 0.5},
+	}, resample.Accumulate, 30*time.Minute)
+	require.NoError(t, err)
+
+	require.Equal(t, map[time.Time]float64{
+		at(0):  2,
+		at(30): 2,
+		at(60): 1.5,
+	}, values(samples))
+
+	total := 0.0
+	for _, sample := range samples {
+		total += sample.Value
+	}
+
+	require.Equal(t, 5.5, total)
+}
+
+func TestResampleErrors(t *testing.T) {
+	_, err := resample.Resample(nil, resample.StepHold, 0)
+	require.Error(t, err)
+
+	_, err = resample.Resample([]resample.Interval{{Start: start, Value: 1}}, resample.Strategy("cubic"), time.Minute)
+	require.Error(t, err)
+
+	_, err = resample.ParseStrategy("cubic")
+	require.Error(t, err)
+
+	strategy, err := resample.ParseStrategy("accumulate")
+	require.NoError(t, err)
+	require.Equal(t, resample.Accumulate, strategy)
+}
@@ -0,0 +1,128 @@
+// Package state persists small pieces of information between runs of a command using a local JSON file.
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type Config struct {
+	Path string `json:"path" usage:"file used to persist state between runs (disabled when empty)"`
+}
+
+// Open loads the state stored at the configured path. A missing file results in an empty Store. When no path is
+// configured, the returned Store is kept in memory and never saved.
+func Open(cfg Config) (*Store, error) {
+	store := &Store{
+		path:   cfg.Path,
+		values: make(map[string]json.RawMessage),
+	}
+
+	if cfg.Path == "" {
+		return store, nil
+	}
+
+	data, err := ioutil.ReadFile(cfg.Path)
+	switch {
+	case os.IsNotExist(err):
+		return store, nil
+	case err != nil:
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &store.values)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Store is a key-value map whose values are encoded as JSON.
+type Store struct {
+	path   string
+	mu     sync.Mutex
+	values map[string]json.RawMessage
+}
+
+// Get decodes the value stored under key into v, reporting whether the key was present.
+func (s *Store) Get(key string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	data, ok := s.values[key]
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, v)
+}
+
+// Set stores v under key. Changes are only persisted once Save is called.
+func (s *Store) Set(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = data
+
+	return nil
+}
+
+// Delete removes the value stored under key.
+func (s *Store) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+}
+
+// Save atomically writes the state to disk by writing to a temporary file and renaming it into place.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.values, "", "  ")
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(s.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,42 @@
+package state_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/state"
+)
+
+func TestStore(t *testing.T) {
+	cfg := state.Config{Path: filepath.Join(t.TempDir(), "homestead", "state.json")}
+
+	store, err := state.Open(cfg)
+	require.NoError(t, err)
+
+	updateTime := time.Date(2022, 1, 12, 15, 0, 0, 0, time.UTC)
+
+	var loaded time.Time
+	ok, err := store.Get("gridpoints/TOP/31,80", &loaded)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, store.Set("gridpoints/TOP/31,80", updateTime))
+	require.NoError(t, store.Save())
+
+	store, err = state.Open(cfg)
+	require.NoError(t, err)
+
+	ok, err = store.Get("gridpoints/TOP/31,80", &loaded)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, updateTime.Equal(loaded))
+
+	store.Delete("gridpoints/TOP/31,80")
+
+	ok, err = store.Get("gridpoints/TOP/31,80", &loaded)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
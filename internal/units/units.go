@@ -0,0 +1,154 @@
+// Package units parses the unit codes returned by weather.gov and converts values between units of the same dimension.
+//
+// weather.gov identifies units using codes from the WMO codes registry, such as "wmoUnit:degC" or "wmoUnit:km_h-1".
+// Older responses use a "unit:" prefix instead, and some NWS specific units use "nwsUnit:".
+package units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Dimension string
+
+const (
+	Dimensionless Dimension = ""
+	Temperature   Dimension = "temperature"
+	Speed         Dimension = "speed"
+	Length        Dimension = "length"
+	Pressure      Dimension = "pressure"
+	Angle         Dimension = "angle"
+	Ratio         Dimension = "ratio"
+	Duration      Dimension = "duration"
+)
+
+// Unit describes how to convert a value to the base unit of its dimension, computed as value*scale + offset.
+type Unit struct {
+	Code      string
+	Dimension Dimension
+	scale     float64
+	offset    float64
+}
+
+func (u Unit) String() string {
+	if u.Code == "" {
+		return "none"
+	}
+
+	return u.Code
+}
+
+var (
+	// None is used for values that have no unit, such as indices. Converting to None leaves values unchanged.
+	None = Unit{}
+
+	Celsius    = Unit{"degC", Temperature, 1, 0}
+	Fahrenheit = Unit{"degF", Temperature, 5.0 / 9.0, -32 * 5.0 / 9.0}
+	Kelvin     = Unit{"K", Temperature, 1, -273.15}
+
+	MetersPerSecond   = Unit{"m_s-1", Speed, 1, 0}
+	KilometersPerHour = Unit{"km_h-1", Speed, 1 / 3.6, 0}
+	MilesPerHour      = Unit{"mi_h-1", Speed, 0.44704, 0}
+	Knots             = Unit{"kt", Speed, 1852.0 / 3600.0, 0}
+
+	Meters      = Unit{"m", Length, 1, 0}
+	Millimeters = Unit{"mm", Length, 0.001, 0}
+	Centimeters = Unit{"cm", Length, 0.01, 0}
+	Kilometers  = Unit{"km", Length, 1000, 0}
+	Inches      = Unit{"in", Length, 0.0254, 0}
+	Feet        = Unit{"ft", Length, 0.3048, 0}
+	Miles       = Unit{"mi", Length, 1609.344, 0}
+
+	Pascals            = Unit{"Pa", Pressure, 1, 0}
+	Hectopascals       = Unit{"hPa", Pressure, 100, 0}
+	Kilopascals        = Unit{"kPa", Pressure, 1000, 0}
+	InchesOfMercury    = Unit{"inHg", Pressure, 3386.389, 0}
+	MillimetersMercury = Unit{"mmHg", Pressure, 133.322387415, 0}
+
+	Degrees = Unit{"degree_(angle)", Angle, 1, 0}
+	Percent = Unit{"percent", Ratio, 1, 0}
+
+	Seconds = Unit{"s", Duration, 1, 0}
+	Minutes = Unit{"min", Duration, 60, 0}
+	Hours   = Unit{"h", Duration, 3600, 0}
+)
+
+var byCode = make(map[string]Unit)
+
+// aliases maps alternate spellings (mostly UCUM, used by older responses) to their WMO code.
+var aliases = map[string]string{
+	"Cel":    "degC",
+	"[degF]": "degF",
+	"km/h":   "km_h-1",
+	"m/s":    "m_s-1",
+	"%":      "percent",
+	"deg":    "degree_(angle)",
+	"mbar":   "hPa",
+}
+
+func init() {
+	for _, unit := range []Unit{
+		Celsius, Fahrenheit, Kelvin,
+		MetersPerSecond, KilometersPerHour, MilesPerHour, Knots,
+		Meters, Millimeters, Centimeters, Kilometers, Inches, Feet, Miles,
+		Pascals, Hectopascals, Kilopascals, InchesOfMercury, MillimetersMercury,
+		Degrees, Percent,
+		Seconds, Minutes, Hours,
+	} {
+		byCode[unit.Code] = unit
+	}
+}
+
+// Parse returns the unit identified by the provided code. Codes may carry a "wmoUnit:", "unit:", or "nwsUnit:" prefix.
+// An error is returned for empty and unknown codes.
+func Parse(code string) (Unit, error) {
+	trimmed := code
+	if i := strings.Index(trimmed, ":"); i >= 0 {
+		trimmed = trimmed[i+1:]
+	}
+
+	if alias, ok := aliases[trimmed]; ok {
+		trimmed = alias
+	}
+
+	unit, ok := byCode[trimmed]
+	if !ok {
+		return None, fmt.Errorf("unknown unit: %q", code)
+	}
+
+	return unit, nil
+}
+
+// Convert converts the value from one unit to another. Converting to None returns the value unchanged. An error is
+// returned when the units measure different dimensions.
+func Convert(value float64, from, to Unit) (float64, error) {
+	switch {
+	case to == None:
+		return value, nil
+	case from.Dimension != to.Dimension:
+		return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+	case from == to:
+		return value, nil
+	}
+
+	base := value*from.scale + from.offset
+	converted := (base - to.offset) / to.scale
+
+	// avoid results like 31.999999999999996 caused by floating point error
+	return math.Round(converted*1e9) / 1e9, nil
+}
+
+// ConvertCode parses the unit code and converts the value into the target unit.
+func ConvertCode(value float64, code string, to Unit) (float64, error) {
+	if to == None {
+		return value, nil
+	}
+
+	from, err := Parse(code)
+	if err != nil {
+		return 0, err
+	}
+
+	return Convert(value, from, to)
+}
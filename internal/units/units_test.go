@@ -0,0 +1,70 @@
+package units_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mjpitz/homestead/internal/units"
+)
+
+func TestParse(t *testing.T) {
+	for code, expected := range map[string]units.Unit{
+		"wmoUnit:degC":           units.Celsius,
+		"unit:degC":              units.Celsius,
+		"wmoUnit:km_h-1":         units.KilometersPerHour,
+		"wmoUnit:percent":        units.Percent,
+		"wmoUnit:degree_(angle)": units.Degrees,
+		"wmoUnit:mm":             units.Millimeters,
+		"wmoUnit:Pa":             units.Pascals,
+		"unit:Cel":               units.Celsius,
+		"nwsUnit:s":              units.Seconds,
+	} {
+		unit, err := units.Parse(code)
+		require.NoError(t, err, code)
+		require.Equal(t, expected, unit, code)
+	}
+
+	for _, code := range []string{"", "wmoUnit:", "wmoUnit:furlong_fortnight-1"} {
+		_, err := units.Parse(code)
+		require.Error(t, err, code)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		value    float64
+		from     units.Unit
+		to       units.Unit
+		expected float64
+	}{
+		{0, units.Celsius, units.Fahrenheit, 32},
+		{100, units.Celsius, units.Fahrenheit, 212},
+		{-40, units.Fahrenheit, units.Celsius, -40},
+		{273.15, units.Kelvin, units.Celsius, 0},
+		{36, units.KilometersPerHour, units.MetersPerSecond, 10},
+		{10, units.MetersPerSecond, units.KilometersPerHour, 36},
+		{1, units.MilesPerHour, units.KilometersPerHour, 1.609344},
+		{25.4, units.Millimeters, units.Inches, 1},
+		{1, units.Kilometers, units.Meters, 1000},
+		{1013.25, units.Hectopascals, units.Pascals, 101325},
+		{1, units.Hours, units.Seconds, 3600},
+		{7, units.Percent, units.None, 7},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := units.Convert(testCase.value, testCase.from, testCase.to)
+		require.NoError(t, err)
+		require.InDelta(t, testCase.expected, actual, 1e-6, "%v %s to %s", testCase.value, testCase.from, testCase.to)
+	}
+
+	_, err := units.Convert(1, units.Celsius, units.Meters)
+	require.Error(t, err)
+
+	_, err = units.ConvertCode(1, "wmoUnit:unknown", units.Celsius)
+	require.Error(t, err)
+
+	value, err := units.ConvertCode(5, "wmoUnit:unknown", units.None)
+	require.NoError(t, err)
+	require.Equal(t, 5.0, value)
+}